@@ -0,0 +1,192 @@
+package gor
+
+import (
+	"fmt"
+	"log"
+)
+
+// ConflictPolicy is how to report the conflicting routes
+type ConflictPolicy int
+
+const (
+	// ConflictWarn print the conflict by the logger, it is the default policy
+	ConflictWarn ConflictPolicy = iota
+	// ConflictPanic panic when routes conflict
+	ConflictPanic
+	// ConflictIgnore ignore the conflict
+	ConflictIgnore
+)
+
+// Logger is used by gor to print warning, *log.Logger implement it
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// conflict kinds
+const (
+	// ConflictDuplicate is same method and same pattern
+	ConflictDuplicate = "duplicate"
+	// ConflictAmbiguous is same method and same pattern, but with different param names, like `/:id` and `/:name`
+	ConflictAmbiguous = "ambiguous"
+	// ConflictShadowed is route registered with method after a same pattern route registered with All
+	ConflictShadowed = "shadowed"
+)
+
+// RouteConflict is two handler routes which match the same requests, the later one is unreachable
+// if the previous one send response
+type RouteConflict struct {
+	Kind string
+
+	Method  string
+	Pattern string
+
+	PreviousMethod  string
+	PreviousPattern string
+}
+
+func (c RouteConflict) String() string {
+	return fmt.Sprintf("route conflict [%s]: %s %s is unreachable, because of %s %s", c.Kind, c.Method, c.Pattern, c.PreviousMethod, c.PreviousPattern)
+}
+
+// SetConflictPolicy set the policy of conflicting routes, and check the registered routes with the policy
+func (g *Gor) SetConflictPolicy(policy ConflictPolicy) {
+	g.conflictPolicy = policy
+	for _, v := range g.Conflicts() {
+		g.reportConflict(v)
+	}
+}
+
+// SetLogger set the logger to print warning and error, default is the standard logger of log package
+func (g *Gor) SetLogger(logger Logger) {
+	g.logger = logger
+}
+
+// Conflicts return all the conflicting routes, including the routes of sub applications under their mount patterns
+func (g *Gor) Conflicts() []RouteConflict {
+	var conflicts []RouteConflict
+	for i, l := range g.tree.leaves {
+		conflicts = append(conflicts, g.tree.conflicts(g.tree.leaves[:i], l)...)
+		if l.route.app == nil {
+			continue
+		}
+		for _, v := range l.route.app.Conflicts() {
+			v.Pattern = joinRoutePath(l.path, v.Pattern)
+			v.PreviousPattern = joinRoutePath(l.path, v.PreviousPattern)
+			conflicts = append(conflicts, v)
+		}
+	}
+	return conflicts
+}
+
+func (g *Gor) reportConflict(c RouteConflict) {
+	switch g.conflictPolicy {
+	case ConflictPanic:
+		panic(c.String())
+	case ConflictWarn:
+		g.logf("[gor] %s", c)
+	}
+}
+
+// logf print by the logger, or the standard logger of log package if it is not set
+func (g *Gor) logf(format string, v ...interface{}) {
+	if g.logger != nil {
+		g.logger.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
+}
+
+// conflicts return the conflicts between l and the previous leaves,
+// the previous leaf whose handlers (the route middleware or the handler) can call next is skipped, because l can be reached after it
+func (t *tree) conflicts(previous []*leaf, l *leaf) []RouteConflict {
+	if l.prefix {
+		return nil
+	}
+
+	var conflicts []RouteConflict
+	for _, p := range previous {
+		if p.prefix || p.hostOf() != l.hostOf() || (t.strictSlash && p.route.trailingSlash != l.route.trailingSlash) {
+			continue
+		}
+		if p.route.canCallNext() {
+			continue
+		}
+
+		kind := ""
+		switch {
+		case p.route.method == l.route.method:
+			if same, sameNames := compareSegments(p.segments, l.segments); !same {
+				continue
+			} else if sameNames {
+				kind = ConflictDuplicate
+			} else {
+				kind = ConflictAmbiguous
+			}
+		case p.route.method == "ALL":
+			if same, _ := compareSegments(p.segments, l.segments); !same {
+				continue
+			}
+			kind = ConflictShadowed
+		default:
+			continue
+		}
+
+		conflicts = append(conflicts, RouteConflict{
+			Kind:            kind,
+			Method:          l.route.method,
+			Pattern:         l.path,
+			PreviousMethod:  p.route.method,
+			PreviousPattern: p.path,
+		})
+	}
+	return conflicts
+}
+
+// compareSegments report whether a and b match the same paths (any expansion of optional segments),
+// and whether they have the same param names
+func compareSegments(a, b []segment) (same bool, sameNames bool) {
+	for la := requiredSegments(a); la <= len(a); la++ {
+		for lb := requiredSegments(b); lb <= len(b); lb++ {
+			if la != lb {
+				continue
+			}
+
+			same, sameNames = true, true
+			for i := 0; i < la; i++ {
+				if a[i].kind != b[i].kind || a[i].constraint != b[i].constraint {
+					same = false
+					break
+				}
+				if a[i].kind == staticSegment && a[i].value != b[i].value {
+					same = false
+					break
+				}
+				if a[i].kind != staticSegment && a[i].value != b[i].value {
+					sameNames = false
+				}
+			}
+			if same {
+				return
+			}
+		}
+	}
+	return false, false
+}
+
+func requiredSegments(segments []segment) int {
+	required := len(segments)
+	for required > 0 && segments[required-1].optional {
+		required--
+	}
+	return required
+}
+
+// canCallNext report whether some handler of r can call next, like next() or next("route")
+func (r *route) canCallNext() bool {
+	for i := 0; i <= len(r.chain); i++ {
+		if h := r.step(i); h.handlerFuncNext != nil || h.errorHandler != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package gor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLogger []string
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, v...))
+}
+
+func TestConflicts(t *testing.T) {
+	as := assert.New(t)
+	h := func(req *Req, res *Res) {}
+
+	logger := new(testLogger)
+	app := NewGor()
+	app.SetLogger(logger)
+
+	app.Use("/a", h)
+	app.Use("/a", h)
+	app.Get("/a", h)
+	app.Post("/a", h)
+	app.Get("/a/", h)
+	app.Get("/users/:id", h)
+	app.Get("/users/:name", h)
+	app.Get("/users/:id<int>", h)
+	app.Get("/users/me", h)
+	app.Get("/posts/:id?", h)
+	app.Get("/posts", h)
+	app.All("/all", h)
+	app.Delete("/all", h)
+	router := NewRouter()
+	router.Get("/1", h)
+	app.Use("/router", router)
+	app.Get("/router/1", h)
+	app.Get("/next", func(req *Req, res *Res, next Next) { next() })
+	app.Get("/next", func(req *Req, res *Res) error { return nil })
+	app.Get("/next", h)
+	app.Post("/skip", func(req *Req, res *Res, next Next) { next("route") }, h)
+	app.Post("/skip", h)
+
+	as.Equal([]string{
+		"[gor] route conflict [duplicate]: GET /a is unreachable, because of GET /a",
+		"[gor] route conflict [ambiguous]: GET /users/:name is unreachable, because of GET /users/:id",
+		"[gor] route conflict [duplicate]: GET /posts is unreachable, because of GET /posts/:id?",
+		"[gor] route conflict [shadowed]: DELETE /all is unreachable, because of ALL /all",
+		"[gor] route conflict [duplicate]: GET /router/1 is unreachable, because of GET /router/1",
+	}, []string(*logger))
+	as.Len(app.Conflicts(), 5)
+
+	as.Panics(func() { app.SetConflictPolicy(ConflictPanic) })
+	as.Panics(func() { app.Get("/users/:x", h) })
+
+	app.SetConflictPolicy(ConflictIgnore)
+	app.Get("/users/:y", h)
+	as.Len(app.Conflicts(), 7)
+	as.Len(*logger, 5)
+
+	router.Get("/1", h)
+	as.Len(router.Routes(), 2)
+}
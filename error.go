@@ -1,6 +1,12 @@
 package gor
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
 
 var (
 	// ErrNotFound is not found error.
@@ -11,4 +17,180 @@ var (
 	ErrJSONMarshal = errors.New("json marshal err")
 	// ErrHTTPStatusCodeInvalid is given http status code is invalid error.
 	ErrHTTPStatusCodeInvalid = errors.New("http status code is invalid")
+	// ErrRouteNotFound is no route registered with the given name error.
+	ErrRouteNotFound = errors.New("route not found")
+	// ErrURLParamMissing is required route param is not given error.
+	ErrURLParamMissing = errors.New("url param missing")
+	// ErrURLParamInvalid is route param not match the constraint error.
+	ErrURLParamInvalid = errors.New("url param invalid")
 )
+
+// errorStatus return the http status code of err by its StatusCode method, 413 if the body is over the limit,
+// 500 if err has no StatusCode method
+func errorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	var coder interface{ StatusCode() int }
+	if errors.As(err, &coder) {
+		if code := coder.StatusCode(); code >= 400 && code < 600 {
+			return code
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// HTTPError is error with http status code
+//
+// Message, Code and Details are responded to the client, Err is the internal cause, which is never responded,
+// but it is in Error() and can be found by errors.Is / errors.As
+type HTTPError struct {
+	Status  int
+	Message string
+	Code    string
+	Details map[string]interface{}
+	Err     error
+}
+
+// NewHTTPError return HTTPError with status code, the message is the status text if not given
+func NewHTTPError(status int, message ...string) *HTTPError {
+	e := &HTTPError{Status: status, Message: http.StatusText(status)}
+	if len(message) > 0 {
+		e.Message = strings.Join(message, " ")
+	}
+	return e
+}
+
+// BadRequestError return 400 HTTPError
+func BadRequestError(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusBadRequest, message...)
+}
+
+// UnauthorizedError return 401 HTTPError
+func UnauthorizedError(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusUnauthorized, message...)
+}
+
+// ForbiddenError return 403 HTTPError
+func ForbiddenError(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusForbidden, message...)
+}
+
+// NotFoundError return 404 HTTPError
+func NotFoundError(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusNotFound, message...)
+}
+
+// MethodNotAllowedError return 405 HTTPError
+func MethodNotAllowedError(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusMethodNotAllowed, message...)
+}
+
+// ConflictError return 409 HTTPError
+func ConflictError(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusConflict, message...)
+}
+
+// UnprocessableEntityError return 422 HTTPError
+func UnprocessableEntityError(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusUnprocessableEntity, message...)
+}
+
+// TooManyRequestsError return 429 HTTPError
+func TooManyRequestsError(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusTooManyRequests, message...)
+}
+
+// InternalServerError return 500 HTTPError
+func InternalServerError(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusInternalServerError, message...)
+}
+
+// PayloadTooLargeError return 413 HTTPError
+func PayloadTooLargeError(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusRequestEntityTooLarge, message...)
+}
+
+// ServiceUnavailableError return 503 HTTPError
+func ServiceUnavailableError(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusServiceUnavailable, message...)
+}
+
+// WithCause return a copy of e with the internal cause err
+func (e *HTTPError) WithCause(err error) *HTTPError {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// WithCode return a copy of e with the error code, like `user_not_found`
+func (e *HTTPError) WithCode(code string) *HTTPError {
+	c := *e
+	c.Code = code
+	return &c
+}
+
+// WithDetails return a copy of e, details are added to the Details of e
+func (e *HTTPError) WithDetails(details map[string]interface{}) *HTTPError {
+	c := *e
+	c.Details = make(map[string]interface{}, len(e.Details)+len(details))
+	for k, v := range e.Details {
+		c.Details[k] = v
+	}
+	for k, v := range details {
+		c.Details[k] = v
+	}
+	return &c
+}
+
+func (e *HTTPError) Error() string {
+	s := strconv.Itoa(e.Status) + " " + e.Message
+	if e.Code != "" {
+		s += " (" + e.Code + ")"
+	}
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+// StatusCode return the http status code
+func (e *HTTPError) StatusCode() int {
+	return e.Status
+}
+
+// Unwrap return the internal cause
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// Is report whether target is HTTPError with the same status, and the same code if the code of target is not empty
+func (e *HTTPError) Is(target error) bool {
+	t, ok := target.(*HTTPError)
+	return ok && t.Status == e.Status && (t.Code == "" || t.Code == e.Code)
+}
+
+// errorf return the error of v, v which is not error is formatted as message of 500 HTTPError
+func errorf(v interface{}) error {
+	if err, ok := v.(error); ok {
+		return err
+	}
+	return InternalServerError(fmt.Sprint(v))
+}
+
+// PanicError is the value recovered from panic, with the stack trace of the panic
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap return the value if it is error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
@@ -0,0 +1,67 @@
+package gor
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPError(t *testing.T) {
+	as := assert.New(t)
+
+	cause := errors.New("sql: no rows")
+	base := NotFoundError("user not found")
+	e := base.WithCode("user_not_found").WithCause(cause).WithDetails(map[string]interface{}{"id": 1})
+	as.Equal("404 user not found (user_not_found): sql: no rows", e.Error())
+	as.Equal(http.StatusNotFound, e.StatusCode())
+	as.Nil(base.Err)
+	as.Equal("", base.Code)
+	as.Nil(base.Details)
+
+	wrapped := fmt.Errorf("get user: %w", e)
+	as.True(errors.Is(wrapped, cause))
+	as.True(errors.Is(wrapped, NotFoundError()))
+	as.True(errors.Is(wrapped, NotFoundError().WithCode("user_not_found")))
+	as.False(errors.Is(wrapped, NotFoundError().WithCode("other")))
+	as.False(errors.Is(wrapped, BadRequestError()))
+	var he *HTTPError
+	as.True(errors.As(wrapped, &he))
+	as.Equal("user not found", he.Message)
+	as.Equal(http.StatusNotFound, errorStatus(wrapped))
+
+	as.Equal("Bad Request", BadRequestError().Message)
+	as.Equal(http.StatusTooManyRequests, TooManyRequestsError().Status)
+	as.Equal(http.StatusInternalServerError, errorStatus(NewHTTPError(http.StatusOK)))
+}
+
+func TestRes_Error(t *testing.T) {
+	app, ts, e, _ := newTestServer(t)
+	defer ts.Close()
+
+	app.Get("/user", func(req *Req, res *Res) error {
+		return NotFoundError("user <1> not found").WithCode("user_not_found").
+			WithDetails(map[string]interface{}{"id": "1"}).WithCause(errors.New("sql: no rows"))
+	})
+	app.Get("/internal", func(req *Req, res *Res) error {
+		return InternalServerError().WithCause(errors.New("dial tcp: connection refused"))
+	})
+	app.Get("/plain", func(req *Req, res *Res) error { return errors.New("plain") })
+	app.Get("/message", func(req *Req, res *Res) { res.Error("message") })
+
+	e.GET("/user").Expect().Status(http.StatusNotFound).Text().Equal("user <1> not found")
+	e.GET("/user").WithHeader("Accept", "application/json").Expect().Status(http.StatusNotFound).
+		JSON().Equal(map[string]interface{}{"status": 404, "message": "user <1> not found", "code": "user_not_found", "details": map[string]interface{}{"id": "1"}})
+	r := e.GET("/user").WithHeader("Accept", "text/html,*/*;q=0.8").Expect().Status(http.StatusNotFound)
+	r.ContentType("text/html", "utf-8")
+	r.Body().Contains("<h1>404 Not Found</h1>").Contains("user &lt;1&gt; not found").Contains("user_not_found").NotContains("sql")
+
+	e.GET("/internal").Expect().Status(http.StatusInternalServerError).Text().Equal("Internal Server Error")
+	e.GET("/internal").WithHeader("Accept", "application/json").Expect().Status(http.StatusInternalServerError).
+		JSON().Equal(map[string]interface{}{"status": 500, "message": "Internal Server Error"})
+	e.GET("/plain").WithHeader("Accept", "application/json").Expect().Status(http.StatusInternalServerError).
+		JSON().Equal(map[string]interface{}{"status": 500, "message": "Internal Server Error"})
+	e.GET("/message").Expect().Status(http.StatusInternalServerError).Text().Equal("message")
+}
@@ -0,0 +1,258 @@
+package gor
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Mode is the mode of Gor to render error
+type Mode int
+
+const (
+	// DefaultMode response the message of error, the Err (internal cause) of HTTPError is not responded,
+	// and the status text is responded for 5xx error which is not HTTPError
+	DefaultMode Mode = iota
+	// DevelopmentMode response the full error, with the stack trace of panic, the request details and the matched routes
+	DevelopmentMode
+	// ProductionMode response generic message for 5xx error (the Message of HTTPError is still responded), with a correlation ID,
+	// the error is printed by the logger with the correlation ID
+	ProductionMode
+)
+
+// ErrorPage is the data of error response
+//
+// it is responded as JSON, or rendered by the template `error` in the render dir (the builtin page if it does not exist),
+// the fields after Development are only set in DevelopmentMode
+type ErrorPage struct {
+	Status        int                    `json:"status"`
+	Title         string                 `json:"-"`
+	Message       string                 `json:"message"`
+	Code          string                 `json:"code,omitempty"`
+	Details       map[string]interface{} `json:"details,omitempty"`
+	CorrelationID string                 `json:"correlation_id,omitempty"`
+
+	Development bool                `json:"-"`
+	Error       string              `json:"error,omitempty"`
+	Stack       string              `json:"stack,omitempty"`
+	Method      string              `json:"method,omitempty"`
+	URL         string              `json:"url,omitempty"`
+	Headers     map[string][]string `json:"headers,omitempty"`
+	Params      map[string]string   `json:"params,omitempty"`
+	// Routes is the routes matched by the request, in the exec order
+	Routes []string `json:"routes,omitempty"`
+}
+
+var errorPage = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{.Status}} {{.Title}}</title></head>
+<body>
+<h1>{{.Status}} {{.Title}}</h1>
+<p>{{.Message}}</p>
+{{- if .Code}}
+<p><code>{{.Code}}</code></p>
+{{- end}}
+{{- if .CorrelationID}}
+<p>Correlation ID: <code>{{.CorrelationID}}</code></p>
+{{- end}}
+{{- if .Development}}
+<h2>Error</h2>
+<pre>{{.Error}}</pre>
+{{- if .Stack}}
+<h2>Stack</h2>
+<pre>{{.Stack}}</pre>
+{{- end}}
+<h2>Request</h2>
+<pre>{{.Method}} {{.URL}}
+{{range $k, $v := .Headers}}{{$k}}: {{range $v}}{{.}} {{end}}
+{{end}}</pre>
+{{- if .Params}}
+<h2>Params</h2>
+<pre>{{range $k, $v := .Params}}{{$k}}: {{$v}}
+{{end}}</pre>
+{{- end}}
+<h2>Routes</h2>
+<pre>{{range .Routes}}{{.}}
+{{end}}</pre>
+{{- end}}
+</body>
+</html>
+`))
+
+// SetMode set the mode to render error, default DefaultMode
+func (g *Gor) SetMode(mode Mode) {
+	g.mode = mode
+}
+
+// errorPage return the error page of err, by the mode of res.app
+func (res *Res) errorPage(err error) *ErrorPage {
+	p := &ErrorPage{Status: errorStatus(err), Message: err.Error()}
+	var e *HTTPError
+	if errors.As(err, &e) {
+		p.Status, p.Message, p.Code, p.Details = errorStatus(e), e.Message, e.Code, e.Details
+	} else if res.app.mode != DevelopmentMode && p.Status >= http.StatusInternalServerError {
+		p.Message = ""
+	}
+	if p.Message == "" {
+		p.Message = http.StatusText(p.Status)
+	}
+	p.Title = http.StatusText(p.Status)
+	if res.app.mode == DefaultMode || res.req == nil {
+		return p
+	}
+
+	p.CorrelationID = res.req.CorrelationID()
+	res.w.Header().Set("X-Request-ID", p.CorrelationID)
+	if res.app.mode == ProductionMode {
+		if p.Status >= http.StatusInternalServerError {
+			res.app.logf("[gor] error [%s] %s %s: %s", p.CorrelationID, res.req.Method, res.req.OriginalURL, err)
+		}
+		return p
+	}
+
+	var pe *PanicError
+	if errors.As(err, &pe) {
+		p.Stack = string(pe.Stack)
+	}
+	p.Development = true
+	p.Error = err.Error()
+	p.Method = res.req.Method
+	p.URL = res.req.r.URL.String()
+	p.Headers = res.req.Headers
+	p.Params = res.req.Params
+	p.Routes = res.app.matchedRoutes(res.req)
+	return p
+}
+
+// renderError response the error page of err, as JSON or HTML if the request Accept it, otherwise as plain text
+func (res *Res) renderError(err error) {
+	if res.exit {
+		return
+	}
+
+	p := res.errorPage(err)
+	res.Status(p.Status)
+	accept := ""
+	if res.req != nil {
+		accept = http.Header(res.req.Headers).Get("Accept")
+	}
+	switch negotiate(accept, "text/plain", "application/json", "text/html") {
+	case "application/json":
+		res.JSON(*p)
+	case "text/html":
+		res.w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if res.render.TemplateLookup("error") != nil && res.render.HTML(res, p.Status, "error", p) == nil {
+			return
+		}
+		var buf strings.Builder
+		if err := errorPage.Execute(&buf, p); err != nil {
+			res.Send(p.Message)
+			return
+		}
+		res.Send(buf.String())
+	default:
+		res.Send(p.text())
+	}
+}
+
+// text return the plain text of the error page
+func (p *ErrorPage) text() string {
+	if p.CorrelationID == "" {
+		return p.Message
+	}
+
+	s := p.Message + "\n\ncorrelation id: " + p.CorrelationID + "\n"
+	if !p.Development {
+		return s
+	}
+	s += "\nerror: " + p.Error + "\n\n" + p.Method + " " + p.URL + "\n"
+	var keys []string
+	for k := range p.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s += k + ": " + strings.Join(p.Headers[k], ", ") + "\n"
+	}
+	if len(p.Routes) > 0 {
+		s += "\nroutes:\n" + strings.Join(p.Routes, "\n") + "\n"
+	}
+	if p.Stack != "" {
+		s += "\n" + p.Stack
+	}
+	return s
+}
+
+// matchedRoutes return the routes matched by the request, like `GET /users/:id github.com/a/b.handler`
+func (g *Gor) matchedRoutes(req *Req) []string {
+	m := acquireMatcher()
+	defer releaseMatcher(m)
+	m.host = strings.TrimSuffix(req.Hostname, ".")
+	g.lookup(m, req.Method, req.Path)
+
+	var routes []string
+	for _, v := range m.matches {
+		if v.leaf.route.notFound != nil {
+			continue
+		}
+		routes = append(routes, v.leaf.route.method+" "+v.leaf.path+" "+v.leaf.route.handlerName())
+	}
+	return routes
+}
+
+// negotiate return the offer with the highest quality in the Accept header, the earlier offer is preferred if the quality is equal,
+// the first offer is returned if accept is empty, and empty string is returned if no offer is acceptable
+func negotiate(accept string, offers ...string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, quality := parseAcceptPart(part)
+			s := acceptSpecificity(mediaType, offer)
+			if s > specificity {
+				q, specificity = quality, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// parseAcceptPart return the media type and the quality of one part of the Accept header, like `text/html;q=0.8`
+func parseAcceptPart(part string) (string, float64) {
+	params := strings.Split(part, ";")
+	q := 1.0
+	for _, v := range params[1:] {
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, "q=") {
+			f, err := strconv.ParseFloat(v[2:], 64)
+			if err != nil {
+				f = 0
+			}
+			q = f
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(params[0])), q
+}
+
+// acceptSpecificity return 2 if mediaType is offer, 1 if mediaType is `type/*` of offer, 0 if `*/*`, -1 if not match
+func acceptSpecificity(mediaType, offer string) int {
+	switch {
+	case mediaType == offer:
+		return 2
+	case mediaType == "*/*" || mediaType == "*":
+		return 0
+	case strings.HasSuffix(mediaType, "/*") && strings.HasPrefix(offer, mediaType[:len(mediaType)-1]):
+		return 1
+	}
+	return -1
+}
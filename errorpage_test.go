@@ -0,0 +1,92 @@
+package gor
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	as := assert.New(t)
+
+	offers := []string{"text/plain", "application/json", "text/html"}
+	for _, v := range []struct {
+		accept string
+		expect string
+	}{
+		{"", "text/plain"},
+		{"*/*", "text/plain"},
+		{"application/json", "application/json"},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "text/html"},
+		{"application/json;q=0.5, text/html;q=0.9", "text/html"},
+		{"text/*", "text/plain"},
+		{"text/*;q=0.5, text/html", "text/html"},
+		{"image/png", ""},
+		{"text/html;q=0", ""},
+	} {
+		as.Equal(v.expect, negotiate(v.accept, offers...), v.accept)
+	}
+}
+
+func TestErrorPage(t *testing.T) {
+	app, ts, e, as := newTestServer(t)
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	app.SetLogger(log.New(&logs, "", 0))
+	app.Use(func(req *Req, res *Res, next Next) {
+		defer func() {
+			if rev := recover(); rev != nil {
+				res.Error(&PanicError{Value: rev, Stack: debug.Stack()})
+			}
+		}()
+		next()
+	})
+	app.Get("/panic/:id", func(req *Req, res *Res) { panic("boom") })
+	app.Get("/internal", func(req *Req, res *Res) error { return errors.New("dial tcp: connection refused") })
+	app.Get("/bad", func(req *Req, res *Res) error { return BadRequestError("name is required") })
+
+	// default mode
+	r := e.GET("/internal").Expect().Status(http.StatusInternalServerError)
+	r.Text().Equal("Internal Server Error")
+	r.Header("X-Request-ID").Empty()
+	e.GET("/panic/1").Expect().Status(http.StatusInternalServerError).Text().Equal("Internal Server Error")
+
+	// production mode
+	app.SetMode(ProductionMode)
+	e.GET("/internal").WithHeader("X-Request-ID", "abc").Expect().Status(http.StatusInternalServerError).
+		Text().Equal("Internal Server Error\n\ncorrelation id: abc\n")
+	as.Equal("[gor] error [abc] GET /internal: dial tcp: connection refused\n", logs.String())
+	r = e.GET("/panic/1").WithHeader("Accept", "application/json").Expect().Status(http.StatusInternalServerError)
+	id := r.Header("X-Request-ID").NotEmpty().Raw()
+	r.JSON().Equal(map[string]interface{}{"status": 500, "message": "Internal Server Error", "correlation_id": id})
+	e.GET("/bad").Expect().Status(http.StatusBadRequest).Text().Contains("name is required")
+	r = e.GET("/internal").WithHeader("Accept", "text/html").Expect().Status(http.StatusInternalServerError)
+	r.Body().Contains("Internal Server Error").Contains(r.Header("X-Request-ID").Raw()).NotContains("dial tcp").NotContains("<h2>Stack</h2>")
+
+	// development mode
+	app.SetMode(DevelopmentMode)
+	obj := e.GET("/panic/1").WithHeader("Accept", "application/json").Expect().Status(http.StatusInternalServerError).JSON().Object()
+	obj.Value("error").Equal("panic: boom")
+	obj.Value("stack").String().Contains("runtime/debug.Stack")
+	obj.Value("method").Equal("GET")
+	obj.Value("url").Equal("/panic/1")
+	obj.Value("params").Equal(map[string]string{"id": "1"})
+	obj.Value("routes").Array().Length().Equal(2)
+	obj.Value("routes").Array().Element(1).String().Contains("GET /panic/:id")
+	e.GET("/internal").WithHeader("Accept", "text/html").Expect().Status(http.StatusInternalServerError).
+		Body().Contains("<h2>Error</h2>").Contains("dial tcp: connection refused").Contains("GET /internal")
+	e.GET("/internal").Expect().Status(http.StatusInternalServerError).
+		Text().Contains("error: dial tcp: connection refused").Contains("routes:\nALL / ")
+
+	// template of the render dir
+	app.SetRenderDir("testdata/errorpage")
+	app.SetMode(DefaultMode)
+	e.GET("/bad").WithHeader("Accept", "text/html").Expect().Status(http.StatusBadRequest).
+		Body().Equal("<h1>400 custom</h1><p>name is required</p>\n")
+}
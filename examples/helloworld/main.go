@@ -32,7 +32,7 @@ func main() {
 
 	app.Get("/panic", func(req *gor.Req, res *gor.Res) {
 		var q *gor.Req = nil
-		res.Status(200).JSON(q.Body)
+		res.Status(200).JSON(q.Params)
 	})
 
 	router := gor.NewRouter()
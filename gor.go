@@ -1,6 +1,9 @@
 package gor
 
-import "strings"
+import (
+	"net/http"
+	"strings"
+)
 
 // Gor gor framework core struct
 type Gor struct {
@@ -8,12 +11,42 @@ type Gor struct {
 	renderDir      string
 	staticFilePath string
 	staticFielDir  string
+
+	methodNotAllowedHandler HandlerFunc
+	optionsHandler          HandlerFunc
+	disableImplicitHead     bool
+	errorHandler            func(err error, req *Req, res *Res)
+	mode                    Mode
+	bodyLimit               BodyLimit
+
+	cleanPath    bool
+	redirectPath bool
+
+	// parent and mountPattern are set when the Gor is mounted as sub application
+	parent       *Gor
+	mountPattern string
+	children     []*Gor
+	onMount      []func(mountPath string)
+
+	conflictPolicy ConflictPolicy
+	logger         Logger
 }
 
 // NewGor return Gor struct
 func NewGor() *Gor {
+	g := newGor(NewRoute())
+	g.tree.app = g
+	g.tree.onConflict = g.reportConflict
+	return g
+}
+
+// newGor return Gor with default settings, which serve the routes of r
+func newGor(r *Route) *Gor {
 	return &Gor{
-		Route: NewRoute(),
+		Route: r,
+
+		methodNotAllowedHandler: defaultMethodNotAllowedHandler,
+		optionsHandler:          defaultOptionsHandler,
 	}
 }
 
@@ -37,3 +70,71 @@ func (g *Gor) SetStaticPath(path string) {
 func (g *Gor) Static(dir string) {
 	g.staticFielDir = dir
 }
+
+// SetMethodNotAllowedHandler set the handler called when the path is registered, but not with the request method
+//
+// the Allow header is set before h is called, set nil to response 404 like the path is not registered
+func (g *Gor) SetMethodNotAllowedHandler(h HandlerFunc) {
+	g.methodNotAllowedHandler = h
+}
+
+// SetOptionsHandler set the handler to answer OPTIONS request of registered path, if it is not registered with Options
+//
+// the Allow header is set before h is called, set nil to disable auto OPTIONS response
+func (g *Gor) SetOptionsHandler(h HandlerFunc) {
+	g.optionsHandler = h
+}
+
+// SetErrorHandler set the handler to response the error which is not handled by ErrorHandlerFunc,
+// the error is passed by next(err) or returned by HandlerFuncWithError / HandlerFuncNextWithError
+//
+// the default handler response the error by Res.Error, set nil to use the default handler,
+// the error of sub application without error handler is passed to the parent
+func (g *Gor) SetErrorHandler(h func(err error, req *Req, res *Res)) {
+	g.errorHandler = h
+}
+
+// SetImplicitHead set whether HEAD request is served by the GET handler (without body) if it is not registered with Head, default true
+func (g *Gor) SetImplicitHead(enable bool) {
+	g.disableImplicitHead = !enable
+}
+
+// SetStrictSlash set whether the trailing slash of path is significant, default false: `/a/` match the route `/a`
+func (g *Gor) SetStrictSlash(strict bool) {
+	g.tree.strictSlash = strict
+}
+
+// SetCaseInsensitive set whether the static segments of path are matched case-insensitively, default false
+func (g *Gor) SetCaseInsensitive(enable bool) {
+	g.tree.caseInsensitive = enable
+}
+
+// SetCleanPath set whether the `//`, `.` and `..` segments of path are cleaned before routing, default false
+func (g *Gor) SetCleanPath(enable bool) {
+	g.cleanPath = enable
+}
+
+// SetRedirectPath set whether to redirect to the canonical path, instead of serving the request, default false
+//
+// the canonical path is the cleaned path when SetCleanPath, or the path with / without trailing slash matched when SetStrictSlash,
+// or the path in the casing of the route matched when SetCaseInsensitive,
+// GET and HEAD are redirected with 301, others are redirected with 308
+func (g *Gor) SetRedirectPath(enable bool) {
+	g.redirectPath = enable
+}
+
+func defaultNotFoundHandler(req *Req, res *Res) {
+	res.Error(NotFoundError())
+}
+
+func defaultErrorHandler(err error, req *Req, res *Res) {
+	res.Error(err)
+}
+
+func defaultMethodNotAllowedHandler(req *Req, res *Res) {
+	res.SendStatus(http.StatusMethodNotAllowed)
+}
+
+func defaultOptionsHandler(req *Req, res *Res) {
+	res.Status(http.StatusNoContent).Write(nil)
+}
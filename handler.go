@@ -1,206 +1,352 @@
 package gor
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
-	"regexp"
+	"path"
 	"strings"
 )
 
-type matchedRouteArray []*route
-
 // ServeHTTP use to start server
 func (g *Gor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	res := httpResponseWriterToRes(w, g)
 	req, err := httpRequestToReq(r)
-
-	if g.staticFilePath == "" {
-		g.staticFilePath = "/static"
-	}
-	if req.Method == http.MethodGet && strings.HasPrefix(req.BaseURL, g.staticFilePath) && g.staticFielDir != "" {
-		http.StripPrefix(g.staticFilePath, http.FileServer(http.Dir(g.staticFielDir))).ServeHTTP(w, r)
+	if err != nil {
+		res.Error(BadRequestError(err.Error()))
 		return
 	}
+	res.req = req
+	defer req.removeTempFiles()
 
-	if err != nil {
-		res.Error(err.Error())
+	if g.serveStatic(req, res) {
 		return
 	}
 
-	requestPath := strings.Split(r.URL.Path, "?")[0]
-	matchedRoutes := matchRouter(r.Method, requestPath, g.routes)
+	g.handle(req, res)
+	if g.notFoundHandler != nil {
+		handleNotFound(g.notFoundHandler, req, res)
+	} else {
+		handleNotFound(defaultNotFoundHandler, req, res)
+	}
+	if req.err != nil && !res.exit {
+		if g.errorHandler != nil {
+			g.errorHandler(req.err, req, res)
+		} else {
+			defaultErrorHandler(req.err, req, res)
+		}
+	}
+	res.SendStatus(http.StatusNotFound)
+	if w, ok := res.w.(*headResponseWriter); ok {
+		w.finish()
+	}
+}
 
-	doHandler(req, res, 0, matchedRoutes, requestPath)
+// handle route the request by req.Path, it do not response 404 when no route handle the request
+func (g *Gor) handle(req *Req, res *Res) {
+	r := req.r
+	if g.cleanPath {
+		if p := cleanPath(req.Path); p != req.Path {
+			if g.redirectPath {
+				redirectPath(res, r, req.MountPath+p)
+				return
+			}
+			req.Path = p
+			req.BaseURL = req.MountPath + p
+		}
+	}
+	if g.redirectPath && g.tree.strictSlash {
+		if p, ok := g.slashRedirect(r.Method, req.Hostname, req.Path); ok {
+			redirectPath(res, r, req.MountPath+p)
+			return
+		}
+	}
+
+	m := acquireMatcher()
+	defer releaseMatcher(m)
+	m.host = strings.TrimSuffix(req.Hostname, ".")
+	head := g.lookup(m, r.Method, req.Path)
+	if g.redirectPath && g.tree.caseInsensitive {
+		if p, ok := m.casedPath(); ok {
+			redirectPath(res, r, req.MountPath+p)
+			return
+		}
+	}
+	if head {
+		// the body is discarded, and the header is written when ServeHTTP finish
+		if _, ok := res.w.(*headResponseWriter); !ok {
+			res.w = &headResponseWriter{ResponseWriter: res.w}
+		}
+	}
 
+	req.bodyLimit = g.bodyLimitOf(req.bodyLimit, m)
+	doHandler(req, res, m, 0, 0)
+	if !res.exit && req.err == nil && m.methodNotAllowed() {
+		g.handleMethodNotAllowed(req, res, m)
+	}
 }
 
-// Listen bind port and start server
-func (g *Gor) Listen(addr string) error {
-	return http.ListenAndServe(addr, g)
+// ServeHTTP serve the request with the routes, like a Gor with default settings
+func (r *Route) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.appOnce.Do(func() {
+		r.app = newGor(r)
+	})
+	r.app.ServeHTTP(w, req)
 }
 
-func doHandler(req *Req, res *Res, index int, matchRoutes []*route, requestPath string) {
-	forLoop := 0
-	for i, j := index, len(matchRoutes); i < j; i++ {
-		forLoop++
-		if res.exit {
-			return
+// ServeHTTP serve the request with h, like a Gor with default settings which use h as the only middleware
+func (h HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g := newGor(NewRoute())
+	g.Use(h)
+	g.ServeHTTP(w, r)
+}
+
+// lookup match the request, and report whether the HEAD request is served by GET handlers
+func (g *Gor) lookup(m *matcher, method, requestPath string) bool {
+	g.tree.lookup(m, method, requestPath)
+	if method != http.MethodHead || g.disableImplicitHead || !m.methodNotAllowed() {
+		return false
+	}
+	for _, v := range m.allowed {
+		if v == http.MethodGet {
+			g.tree.lookup(m, http.MethodGet, requestPath)
+			return true
 		}
+	}
+	return false
+}
 
-		route := matchRoutes[i]
-		req.Params, _ = matchPath(route.routePath, requestPath, route.matchType)
+// slashRedirect return the path with / without trailing slash, if only it is matched by some handlers
+func (g *Gor) slashRedirect(method, host, requestPath string) (string, bool) {
+	if requestPath == "/" {
+		return "", false
+	}
 
-		if route.handlerFunc != nil {
-			route.handlerFunc(req, res)
-		} else if route.handlerFuncNext != nil {
-			noCallNext := true
-			route.handlerFuncNext(req, res, func(errs ...string) {
-				if len(errs) > 0 {
-					res.Error(strings.Join(errs, ", "))
-					return
-				}
-				noCallNext = false
-				doHandler(req, res, index+1, matchRoutes, requestPath)
-			})
-			if noCallNext {
-				res.exit = true
-				return
-			}
-		} else {
-			panic("This can not exist when handler the request, this is a bug, please report : https://github.com/Chyroc/gor/issues")
-		}
+	m := acquireMatcher()
+	defer releaseMatcher(m)
+	m.host = host
+	if g.lookup(m, method, requestPath); m.hasHandler() || len(m.allowed) > 0 {
+		return "", false
 	}
 
-	if forLoop == len(matchRoutes) {
-		res.SendStatus(http.StatusNotFound)
+	if strings.HasSuffix(requestPath, "/") {
+		requestPath = strings.TrimRight(requestPath, "/")
+	} else {
+		requestPath += "/"
 	}
+	if g.lookup(m, method, requestPath); m.hasHandler() || len(m.allowed) > 0 {
+		return requestPath, true
+	}
+	return "", false
 }
 
-func matchRouter(method string, requestPath string, routes []*route) []*route {
-	if strings.ContainsRune(requestPath, '?') {
-		requestPath = strings.Split(requestPath, "?")[0]
+// redirectPath redirect to the path with the query of r, GET and HEAD are redirected with 301, others are redirected with 308
+//
+// the leading `/` and `\` of path are collapsed to one `/`, `//host` is the URL of other host for the browser
+func redirectPath(res *Res, r *http.Request, path string) {
+	path = "/" + strings.TrimLeft(path, `/\`)
+	code := http.StatusPermanentRedirect
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		code = http.StatusMovedPermanently
+	}
+	if r.URL.RawQuery != "" {
+		path += "?" + r.URL.RawQuery
 	}
-	var matchedRoutes matchedRouteArray
-	recursionMatch(method, requestPath, "", routes, &matchedRoutes)
-	return matchedRoutes
+	res.exit = true
+	http.Redirect(res.w, r, path, code)
 }
 
-func recursionMatch(method, requestPath, prePath string, parentRoutes []*route, matchedRoutes *matchedRouteArray) {
-	if !strings.HasPrefix(requestPath, "/") {
-		requestPath = "/" + requestPath
+// cleanPath clean the `//`, `.` and `..` segments of p, and keep the trailing slash
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
 	}
-	if strings.HasSuffix(requestPath, "/") {
-		requestPath = requestPath[:len(requestPath)-1]
+	c := path.Clean("/" + p)
+	if p[len(p)-1] == '/' && c != "/" {
+		c += "/"
+	}
+	return c
+}
+
+func (g *Gor) handleMethodNotAllowed(req *Req, res *Res, m *matcher) {
+	h := g.methodNotAllowedHandler
+	if req.Method == http.MethodOptions && g.optionsHandler != nil {
+		h = g.optionsHandler
+	}
+	if h == nil {
+		return
+	}
+
+	if !g.disableImplicitHead {
+		for _, v := range m.allowed {
+			if v == http.MethodGet {
+				m.allow(http.MethodHead)
+				break
+			}
+		}
 	}
-	for _, route := range parentRoutes {
-		if route.method != "ALL" && route.method != method {
+	if g.optionsHandler != nil {
+		m.allow(http.MethodOptions)
+	}
+	req.allowed = append([]string(nil), m.allowed...)
+	res.w.Header().Set("Allow", strings.Join(req.allowed, ", "))
+	h(req, res)
+}
+
+// Listen bind port and start server
+func (g *Gor) Listen(addr string) error {
+	return http.ListenAndServe(addr, g)
+}
+
+// doHandler exec the handlers from the step-th handler of the index-th match
+func doHandler(req *Req, res *Res, m *matcher, index, step int) {
+	for i := index; i < len(m.matches); i, step = i+1, 0 {
+		if res.exit {
+			return
+		}
+
+		route := m.matches[i].leaf.route
+		req.matched = m.matches[i].leaf
+		req.Params = m.params(i)
+		for k, v := range req.mountParams {
+			if _, ok := req.Params[k]; !ok {
+				req.Params[k] = v
+			}
+		}
+		// the not found handler of Router serve the request which is not handled by the Router, except method not allowed
+		if route.notFound != nil {
+			if !m.methodNotAllowed() {
+				handleNotFound(route.notFound, req, res)
+			}
 			continue
 		}
-		mType := route.matchType
-		if mType == onlyLastFull {
-			if len(route.children) > 0 {
-				mType = preMatch
-			} else {
-				mType = fullMatch
+		if req.err == nil {
+			if h, value, ok := req.nextParamHandler(req.matched); ok {
+				doParamHandler(req, res, m, i, step, h, value)
+				return
 			}
 		}
 
-		_, matched := matchPath(route.routePath, requestPath, mType)
+		for j := step; j <= len(route.chain); j++ {
+			if res.exit {
+				return
+			}
 
-		if matched {
-			if len(route.children) > 0 {
-				subRequestPath := strings.Join(strings.Split(requestPath, "/")[2:], "/")
-				recursionMatch(method, subRequestPath, prePath+route.routePath, route.children, matchedRoutes)
-			} else {
-				route2 := route.copy()
-				route2.routePath = prePath + route2.routePath
-				*matchedRoutes = append(*matchedRoutes, route2)
+			// ErrorHandlerFunc only exec when there is error, and the others only exec when there is no error
+			h := route.step(j)
+			if (h.errorHandler != nil) != (req.err != nil) {
+				continue
 			}
-		}
-	}
-}
 
-func genMatchPathReg(routePath string) *regexp.Regexp {
-	if strings.HasSuffix(routePath, "/") {
-		routePath = routePath[:len(routePath)-1]
-	}
+			if h.handlerFunc != nil {
+				h.handlerFunc(req, res)
+				continue
+			}
 
-	if strings.ContainsRune(routePath, ':') {
-		routePaths := strings.Split(routePath, "/")
-		var regS []string
-		for _, v := range routePaths {
-			if strings.HasPrefix(v, ":") {
-				regS = append(regS, `(?P<`+strings.ToLower(v[1:])+`>.*)`)
+			noCallNext := true
+			next := func(args ...interface{}) {
+				nextIndex, nextStep, err := nextStepOf(m, i, j, args)
+				req.err = err
+				noCallNext = false
+				doHandler(req, res, m, nextIndex, nextStep)
+			}
+			if h.handlerFuncNext != nil {
+				h.handlerFuncNext(req, res, next)
+			} else if h.errorHandler != nil {
+				h.errorHandler(req.err, req, res, next)
 			} else {
-				regS = append(regS, v)
+				panic("This can not exist when handler the request, this is a bug, please report : https://github.com/Chyroc/gor/issues")
 			}
+			if noCallNext {
+				res.exit = true
+			}
+			return
 		}
-		return regexp.MustCompile(strings.Join(regS, "/"))
 	}
+}
 
-	return nil
+// handleNotFound serve the request by the not found handler h, if it is not handled, or failed with not found error
+//
+// the status code is 404 unless h change it, and 404 is responded if h do not response
+func handleNotFound(h HandlerFunc, req *Req, res *Res) {
+	if res.exit || (req.err != nil && !isNotFound(req.err)) {
+		return
+	}
+	req.err = nil
+	res.Status(http.StatusNotFound)
+	h(req, res)
+	res.SendStatus(http.StatusNotFound)
 }
 
-// matchtype pre full onlyLastFull
-func matchPath(routePath, requestPath string, matchtype matchType) (params map[string]string, matched bool) {
-	if !strings.HasPrefix(routePath, "/") {
-		routePath = "/" + routePath
+// isNotFound report whether err is ErrNotFound or 404 error, the HTTPError with the message, code or details is not,
+// because it is responded by the error handler with them
+func isNotFound(err error) bool {
+	if errors.Is(err, ErrNotFound) {
+		return true
 	}
-	if strings.HasSuffix(routePath, "/") {
-		routePath = routePath[:len(routePath)-1]
+	var e *HTTPError
+	if errors.As(err, &e) {
+		return e.Status == http.StatusNotFound && e.Message == http.StatusText(http.StatusNotFound) && e.Code == "" && e.Details == nil
 	}
+	return errorStatus(err) == http.StatusNotFound
+}
 
-	if strings.HasSuffix(requestPath, "/") {
-		requestPath = requestPath[:len(requestPath)-1]
+// doParamHandler exec the param handler h before the step-th handler of the index-th match
+func doParamHandler(req *Req, res *Res, m *matcher, index, step int, h ParamHandler, value string) {
+	noCallNext := true
+	h(req, res, func(args ...interface{}) {
+		nextIndex, nextStep := index, step
+		if len(args) > 0 {
+			// param handler is like the last handler of the route, when next with "route", "router" or error
+			nextIndex, nextStep, req.err = nextStepOf(m, index, len(m.matches[index].leaf.route.chain), args)
+		}
+		noCallNext = false
+		doHandler(req, res, m, nextIndex, nextStep)
+	}, value)
+	if noCallNext {
+		res.exit = true
 	}
+}
 
-	containsColon := strings.ContainsRune(routePath, ':')
-	var reg *regexp.Regexp
-	if containsColon {
-		reg = genMatchPathReg(routePath)
+// nextStepOf return the (index, step) to exec after the j-th handler of the i-th match by the args of Next,
+// and the error passed to next, the invalid args is also returned as error, the nil args are ignored, like next(err) with nil err
+func nextStepOf(m *matcher, i, j int, args []interface{}) (int, int, error) {
+	index, step := i+1, 0
+	if j < len(m.matches[i].leaf.route.chain) {
+		index, step = i, j+1
 	}
-
-	params = make(map[string]string)
-	matched = false
-
-	switch matchtype {
-	case fullMatch:
-		if containsColon {
-			if len(strings.Split(routePath, "/")) != len(strings.Split(requestPath, "/")) {
-				return
-			}
-			paramsMap := make(map[string]string)
-			match := reg.FindStringSubmatch(requestPath)
-			for i, name := range reg.SubexpNames() {
-				if i > 0 && i <= len(match) {
-					paramsMap[name] = match[i]
-				}
-			}
-			if len(match) > 0 {
-				return paramsMap, true
-			}
-			return
+	nonNil := args[:0:0]
+	for _, v := range args {
+		if v != nil {
+			nonNil = append(nonNil, v)
 		}
-		return make(map[string]string), routePath == requestPath
-	case preMatch:
-		if containsColon {
-			if len(strings.Split(routePath, "/")) > len(strings.Split(requestPath, "/")) {
-				return
-			}
-
-			paramsMap := make(map[string]string)
-			match := reg.FindStringSubmatch(strings.Join(strings.Split(requestPath, "/")[:len(strings.Split(routePath, "/"))], "/"))
-			for i, name := range reg.SubexpNames() {
-				if i > 0 && i <= len(match) {
-					paramsMap[name] = match[i]
-				}
-			}
+	}
+	args = nonNil
+	if len(args) == 0 {
+		return index, step, nil
+	}
 
-			if len(match) > 0 {
-				return paramsMap, true
+	switch v := args[0].(type) {
+	case string:
+		if len(args) == 1 && v == "route" {
+			return i + 1, 0, nil
+		} else if len(args) == 1 && v == "router" {
+			return m.exitRouter(i), 0, nil
+		}
+	case error:
+		msgs := []string{v.Error()}
+		for _, arg := range args[1:] {
+			err, ok := arg.(error)
+			if !ok {
+				return index, step, fmt.Errorf("next only accept errors after error, but get %#v", arg)
 			}
-			return
+			msgs = append(msgs, err.Error())
+		}
+		if len(msgs) == 1 {
+			return index, step, v
 		}
-		return make(map[string]string), strings.HasPrefix(requestPath, routePath) && (len(requestPath) == len(routePath) || strings.HasPrefix(requestPath[len(routePath):], "/"))
+		return index, step, errors.New(strings.Join(msgs, ", "))
 	}
-	return
+
+	return index, step, fmt.Errorf("next only accept \"route\", \"router\" or error, but get %#v", args)
 }
@@ -1,30 +1,44 @@
 package gor
 
 import (
+	"errors"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/gavv/httpexpect"
 	"github.com/stretchr/testify/assert"
 )
 
+// lookupPattern return the params of requestPath matched by the route of routePath
+func lookupPattern(routePath, requestPath string, matchType matchType) (map[string]string, bool) {
+	t := newTree()
+	t.insert("", nil, &route{method: "ALL", routePath: routePath, matchType: matchType})
+	_, params := lookupPaths(t, "ALL", requestPath)
+	if len(params) == 0 {
+		return map[string]string{}, false
+	}
+	return params[0], true
+}
+
 func TestMatchReg(t *testing.T) {
 	as := assert.New(t)
 
 	{
 		var noRegMatch = func(matchType matchType) {
-			params, matched := matchPath("/a", "/a", matchType)
+			params, matched := lookupPattern("/a", "/a", matchType)
 			as.Equal(params, map[string]string{})
 			as.True(matched)
 
-			params, matched = matchPath("/a/", "/a/", matchType)
+			params, matched = lookupPattern("/a/", "/a/", matchType)
 			as.Equal(params, map[string]string{})
 			as.True(matched)
 
-			params, matched = matchPath("/a", "/a/", matchType)
+			params, matched = lookupPattern("/a", "/a/", matchType)
 			as.Equal(params, map[string]string{})
 			as.True(matched)
 
-			params, matched = matchPath("/a/", "/a", matchType)
+			params, matched = lookupPattern("/a/", "/a", matchType)
 			as.Equal(params, map[string]string{})
 			as.True(matched)
 		}
@@ -35,46 +49,46 @@ func TestMatchReg(t *testing.T) {
 			// premathc + no-reg
 			noRegMatch(preMatch)
 
-			params, matched := matchPath("/", "/a", preMatch)
+			params, matched := lookupPattern("/", "/a", preMatch)
 			as.Equal(params, map[string]string{})
 			as.True(matched)
 
-			params, matched = matchPath("/a/", "/a/b/c", preMatch)
+			params, matched = lookupPattern("/a/", "/a/b/c", preMatch)
 			as.Equal(params, map[string]string{})
 			as.True(matched)
 
-			params, matched = matchPath("/a/b", "/a/b/c", preMatch)
+			params, matched = lookupPattern("/a/b", "/a/b/c", preMatch)
 			as.Equal(params, map[string]string{})
 			as.True(matched)
 
-			params, matched = matchPath("/a", "/", preMatch)
+			params, matched = lookupPattern("/a", "/", preMatch)
 			as.Equal(params, map[string]string{})
 			as.False(matched)
 		}
 	}
 	{
 		var realRegMatch = func(matchtype matchType) {
-			params, matched := matchPath("/:aname", "/", matchtype)
+			params, matched := lookupPattern("/:aname", "/", matchtype)
 			as.Equal(params, map[string]string{})
 			as.False(matched)
 
-			params, matched = matchPath("/:aname", "/a", matchtype)
+			params, matched = lookupPattern("/:aname", "/a", matchtype)
 			as.Equal(map[string]string{"aname": "a"}, params)
 			as.True(matched)
 
-			params, matched = matchPath("/:ANAME", "/a/", matchtype)
+			params, matched = lookupPattern("/:ANAME", "/a/", matchtype)
 			as.Equal(map[string]string{"aname": "a"}, params)
 			as.True(matched)
 
-			params, matched = matchPath("/:user/:name", "/a/b", matchtype)
+			params, matched = lookupPattern("/:user/:name", "/a/b", matchtype)
 			as.Equal(map[string]string{"user": "a", "name": "b"}, params)
 			as.True(matched)
 
-			params, matched = matchPath("/:user/no-param/:name", "/a/no-param/b", matchtype)
+			params, matched = lookupPattern("/:user/no-param/:name", "/a/no-param/b", matchtype)
 			as.Equal(map[string]string{"user": "a", "name": "b"}, params)
 			as.True(matched)
 
-			params, matched = matchPath("/:user/no-param/:name", "/a/oh-no/b", matchtype)
+			params, matched = lookupPattern("/:user/no-param/:name", "/a/oh-no/b", matchtype)
 			as.Equal(params, map[string]string{})
 			as.False(matched)
 		}
@@ -82,7 +96,7 @@ func TestMatchReg(t *testing.T) {
 			// fullmatch + reg
 			realRegMatch(fullMatch)
 
-			params, matched := matchPath("/:aname", "/a/b", fullMatch)
+			params, matched := lookupPattern("/:aname", "/a/b", fullMatch)
 			as.Equal(params, map[string]string{})
 			as.False(matched)
 		}
@@ -90,19 +104,19 @@ func TestMatchReg(t *testing.T) {
 			// fprematch + reg
 			realRegMatch(preMatch)
 
-			params, matched := matchPath("/:a", "/a/b", preMatch)
+			params, matched := lookupPattern("/:a", "/a/b", preMatch)
 			as.Equal(map[string]string{"a": "a"}, params)
 			as.True(matched)
 
-			params, matched = matchPath("/:a", "/a/b/c/d", preMatch)
+			params, matched = lookupPattern("/:a", "/a/b/c/d", preMatch)
 			as.Equal(map[string]string{"a": "a"}, params)
 			as.True(matched)
 
-			params, matched = matchPath("/:a/:b", "/a/b/c/d", preMatch)
+			params, matched = lookupPattern("/:a/:b", "/a/b/c/d", preMatch)
 			as.Equal(map[string]string{"a": "a", "b": "b"}, params)
 			as.True(matched)
 
-			params, matched = matchPath("/:a/user", "/a/user/:b", preMatch)
+			params, matched = lookupPattern("/:a/user", "/a/user/:b", preMatch)
 			as.Equal(map[string]string{"a": "a"}, params)
 			as.True(matched)
 		}
@@ -122,3 +136,221 @@ func TestStatic(t *testing.T) {
 	e.GET("/files/").Expect().Status(http.StatusOK).Body().Equal("<pre>\n<a href=\"github.com/\">github.com/</a>\n<a href=\"golang.org/\">golang.org/</a>\n</pre>\n")
 	e.GET("/files/github.com/unrolled/render/LICENSE").Expect().Status(http.StatusOK).Body().Contains("The MIT License (MIT)")
 }
+
+func TestServeHTTP_router_handlerFunc(t *testing.T) {
+	as := assert.New(t)
+
+	router := NewRouter()
+	router.Use(func(req *Req, res *Res, next Next) {
+		res.AddHeader("X-Router", "1")
+		next()
+	})
+	router.Get("/users/:id", func(req *Req, res *Res) { res.JSON(req.Params) })
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+	e := httpexpect.New(t, ts.URL)
+
+	r := e.GET("/users/1").Expect().Status(http.StatusOK)
+	r.JSON().Equal(map[string]string{"id": "1"})
+	r.Header("X-Router").Equal("1")
+	e.GET("/users").Expect().Status(http.StatusNotFound).Text().Equal("Not Found")
+	e.POST("/users/1").Expect().Status(http.StatusMethodNotAllowed).Header("Allow").Equal("GET, HEAD, OPTIONS")
+
+	router.Get("/later", func(req *Req, res *Res) { res.Send("later") })
+	e.GET("/later").Expect().Status(http.StatusOK).Text().Equal("later")
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api", router))
+	mux.Handle("/hello", HandlerFunc(func(req *Req, res *Res) { res.Send("hello " + req.Query["name"][0]) }))
+	mux.Handle("/empty", HandlerFunc(func(req *Req, res *Res) {}))
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/2", nil))
+	as.Equal(http.StatusOK, w.Code)
+	as.Equal("{\"id\":\"2\"}", w.Body.String())
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/hello?name=gor", nil))
+	as.Equal(http.StatusOK, w.Code)
+	as.Equal("hello gor", w.Body.String())
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/empty", nil))
+	as.Equal(http.StatusNotFound, w.Code)
+}
+
+func TestPathPolicy(t *testing.T) {
+	as := assert.New(t)
+
+	newApp := func() *Gor {
+		app := NewGor()
+		app.Get("/a", func(req *Req, res *Res) { res.Send("a " + req.BaseURL) })
+		app.Get("/b/", func(req *Req, res *Res) { res.Send("b " + req.BaseURL) })
+		app.Post("/Users/:name", func(req *Req, res *Res) { res.Send("user " + req.Params["name"]) })
+		app.Get("/a/b/c", func(req *Req, res *Res) { res.Send("c " + req.BaseURL) })
+		return app
+	}
+	serve := func(app *Gor, method, path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/?q=1", nil)
+		r.URL.Path = path
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	{
+		// default: lenient slash, case-sensitive, no clean, no redirect
+		app := newApp()
+		as.Equal("a /a/", serve(app, http.MethodGet, "/a/").Body.String())
+		as.Equal("b /b", serve(app, http.MethodGet, "/b").Body.String())
+		as.Equal("a //a", serve(app, http.MethodGet, "//a").Body.String())
+		as.Equal(http.StatusNotFound, serve(app, http.MethodGet, "/a/b/../b/c").Code)
+		as.Equal(http.StatusNotFound, serve(app, http.MethodPost, "/users/x").Code)
+	}
+
+	{
+		app := newApp()
+		app.SetStrictSlash(true)
+		app.SetCaseInsensitive(true)
+		app.SetCleanPath(true)
+		as.Equal("a /a", serve(app, http.MethodGet, "/a").Body.String())
+		as.Equal(http.StatusNotFound, serve(app, http.MethodGet, "/a/").Code)
+		as.Equal("b /b/", serve(app, http.MethodGet, "/b/").Body.String())
+		as.Equal(http.StatusNotFound, serve(app, http.MethodGet, "/b").Code)
+		as.Equal("a /a", serve(app, http.MethodGet, "//a").Body.String())
+		as.Equal("c /a/b/c", serve(app, http.MethodGet, "/a/./b/../b/c").Body.String())
+		as.Equal("user Name", serve(app, http.MethodPost, "/USERS/Name").Body.String())
+		as.Equal(http.StatusNotFound, serve(app, http.MethodPost, "/users/name/").Code)
+		as.Len(app.Conflicts(), 0)
+	}
+
+	{
+		app := newApp()
+		app.SetStrictSlash(true)
+		app.SetCleanPath(true)
+		app.SetRedirectPath(true)
+
+		w := serve(app, http.MethodGet, "/a/")
+		as.Equal(http.StatusMovedPermanently, w.Code)
+		as.Equal("/a?q=1", w.Header().Get("Location"))
+
+		w = serve(app, http.MethodGet, "/b")
+		as.Equal(http.StatusMovedPermanently, w.Code)
+		as.Equal("/b/?q=1", w.Header().Get("Location"))
+
+		w = serve(app, http.MethodPost, "/Users/name/")
+		as.Equal(http.StatusPermanentRedirect, w.Code)
+		as.Equal("/Users/name?q=1", w.Header().Get("Location"))
+
+		w = serve(app, http.MethodGet, "/a/../a//b/./c")
+		as.Equal(http.StatusMovedPermanently, w.Code)
+		as.Equal("/a/b/c?q=1", w.Header().Get("Location"))
+
+		as.Equal(http.StatusNotFound, serve(app, http.MethodGet, "/not-exist/").Code)
+		as.Equal("a /a", serve(app, http.MethodGet, "/a").Body.String())
+	}
+
+	{
+		// the redirect never point to other host
+		app := NewGor()
+		app.SetStrictSlash(true)
+		app.SetCaseInsensitive(true)
+		app.SetRedirectPath(true)
+		app.Get("/:user", func(req *Req, res *Res) {})
+		app.Get("/Evil.com/:x", func(req *Req, res *Res) {})
+
+		w := serve(app, http.MethodGet, "//evil.com/")
+		as.Equal(http.StatusMovedPermanently, w.Code)
+		as.Equal("/evil.com?q=1", w.Header().Get("Location"))
+
+		w = serve(app, http.MethodGet, "//evil.com/x")
+		as.Equal(http.StatusMovedPermanently, w.Code)
+		as.Equal("/Evil.com/x?q=1", w.Header().Get("Location"))
+
+		w = serve(app, http.MethodGet, `/\evil.com/`)
+		as.Equal(http.StatusMovedPermanently, w.Code)
+		as.Equal("/evil.com?q=1", w.Header().Get("Location"))
+	}
+
+	{
+		app := newApp()
+		app.SetCaseInsensitive(true)
+		app.SetRedirectPath(true)
+
+		w := serve(app, http.MethodGet, "/A")
+		as.Equal(http.StatusMovedPermanently, w.Code)
+		as.Equal("/a?q=1", w.Header().Get("Location"))
+
+		w = serve(app, http.MethodPost, "/USERS/Name")
+		as.Equal(http.StatusPermanentRedirect, w.Code)
+		as.Equal("/Users/Name?q=1", w.Header().Get("Location"))
+
+		w = serve(app, http.MethodGet, "/A/b/C")
+		as.Equal(http.StatusMovedPermanently, w.Code)
+		as.Equal("/a/b/c?q=1", w.Header().Get("Location"))
+
+		as.Equal("user Name", serve(app, http.MethodPost, "/Users/Name").Body.String())
+		as.Equal("a /a", serve(app, http.MethodGet, "/a").Body.String())
+	}
+
+	{
+		app := NewGor()
+		app.Get("/b/", func(req *Req, res *Res) {}).Name("b")
+		u, err := app.URLFor("b", nil, nil)
+		as.Nil(err)
+		as.Equal("/b/", u)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	app, ts, e, _ := newTestServer(t)
+	defer ts.Close()
+
+	api := NewRouter()
+	api.SetNotFoundHandler(func(req *Req, res *Res) { res.JSON(map[string]string{"error": "no api " + req.Path}) })
+	api.Get("/users", func(req *Req, res *Res) { res.Send("users") })
+
+	sub := NewGor()
+	sub.SetNotFoundHandler(func(req *Req, res *Res) { res.Send("no sub " + req.Path) })
+	sub.Get("/missing", func(req *Req, res *Res, next Next) { next(ErrNotFound) })
+
+	app.Static("./vendor")
+	app.Use("/api", api)
+	app.Use("/sub", sub)
+	app.Get("/missing", func(req *Req, res *Res) error { return ErrNotFound })
+	app.Get("/missing-http", func(req *Req, res *Res) error { return NotFoundError().WithCause(errors.New("no rows")) })
+	app.Get("/missing-status", func(req *Req, res *Res) error { return statusTestError(http.StatusNotFound) })
+	app.Get("/missing-user", func(req *Req, res *Res) error { return NotFoundError("no user") })
+
+	e.GET("/none").Expect().Status(http.StatusNotFound).Text().Equal("Not Found")
+	e.GET("/none").WithHeader("Accept", "application/json").Expect().Status(http.StatusNotFound).
+		JSON().Equal(map[string]interface{}{"status": 404, "message": "Not Found"})
+	e.GET("/missing").Expect().Status(http.StatusNotFound).Text().Equal("Not Found")
+
+	e.GET("/api/users").Expect().Status(http.StatusOK).Text().Equal("users")
+	e.GET("/api/none").Expect().Status(http.StatusNotFound).JSON().Equal(map[string]string{"error": "no api /api/none"})
+	e.POST("/api/users").Expect().Status(http.StatusMethodNotAllowed)
+	e.GET("/sub/none").Expect().Status(http.StatusNotFound).Text().Equal("no sub /none")
+	e.GET("/sub/missing").Expect().Status(http.StatusNotFound).Text().Equal("no sub /missing")
+	e.GET("/static/none").Expect().Status(http.StatusNotFound).Text().Equal("Not Found")
+
+	app.SetNotFoundHandler(func(req *Req, res *Res) {
+		if req.Path == "/app" {
+			res.Status(http.StatusOK).Send("index")
+			return
+		}
+		res.Send("no " + req.Path)
+	})
+	e.GET("/none").Expect().Status(http.StatusNotFound).Text().Equal("no /none")
+	e.GET("/missing").Expect().Status(http.StatusNotFound).Text().Equal("no /missing")
+	e.GET("/missing-http").Expect().Status(http.StatusNotFound).Text().Equal("no /missing-http")
+	e.GET("/missing-status").Expect().Status(http.StatusNotFound).Text().Equal("no /missing-status")
+	e.GET("/missing-user").Expect().Status(http.StatusNotFound).Text().Equal("no user")
+	e.GET("/static/none").Expect().Status(http.StatusNotFound).Text().Equal("no /static/none")
+	e.GET("/app").Expect().Status(http.StatusOK).Text().Equal("index")
+	e.GET("/api/none").Expect().Status(http.StatusNotFound).JSON().Equal(map[string]string{"error": "no api /api/none"})
+
+	app.SetNotFoundHandler(func(req *Req, res *Res) {})
+	e.GET("/none").Expect().Status(http.StatusNotFound).Text().Equal("Not Found")
+}
@@ -0,0 +1,146 @@
+package gor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hostPattern is the parsed host pattern of Route.Host
+//
+// labels are split by `.`, `:tenant` match one label, `*` or `*name` match one or more labels,
+// and can only be the first or the last label
+type hostPattern struct {
+	pattern  string
+	segments []segment
+	catchAll bool
+
+	// names and matchers of the named param labels, in order
+	keys     []string
+	matchers []ParamMatcher
+}
+
+func parseHostPattern(pattern string) *hostPattern {
+	if pattern == "" {
+		panic("host pattern cannot be empty")
+	}
+
+	h := &hostPattern{pattern: pattern}
+	labels := strings.Split(pattern, ".")
+	for i, v := range labels {
+		if v == "" {
+			panic(fmt.Sprintf("host label cannot be empty: %s", pattern))
+		}
+		s, err := parseSegment(v)
+		if err != nil {
+			panic(fmt.Sprintf("host pattern invalid: %s, %s", pattern, err))
+		}
+		switch {
+		case s.kind == paramSegment && (s.value == "" || s.optional):
+			panic(fmt.Sprintf("host param must be named and required: %s", pattern))
+		case s.kind == catchAllSegment && (h.catchAll || (i != 0 && i != len(labels)-1)):
+			panic(fmt.Sprintf("only one of the first or the last host label can be *: %s", pattern))
+		case s.kind == catchAllSegment:
+			h.catchAll = true
+		}
+		if s.kind != staticSegment && s.value != "" {
+			h.keys = append(h.keys, s.value)
+			h.matchers = append(h.matchers, s.matcher)
+		}
+		h.segments = append(h.segments, s)
+	}
+	return h
+}
+
+// match report whether host match the pattern, the values of the named labels are appended to values
+func (h *hostPattern) match(host string, values []string) ([]string, bool) {
+	n := strings.Count(host, ".") + 1
+	wild := n - len(h.segments) + 1
+	if (!h.catchAll && n != len(h.segments)) || (h.catchAll && wild < 1) {
+		return values, false
+	}
+
+	pos := 0
+	for _, seg := range h.segments {
+		start := pos
+		var label string
+		if seg.kind == catchAllSegment {
+			for i := 0; i < wild; i++ {
+				_, pos = nextHostLabel(host, pos)
+			}
+			label = host[start : pos-1]
+		} else {
+			label, pos = nextHostLabel(host, pos)
+		}
+
+		switch seg.kind {
+		case staticSegment:
+			if !strings.EqualFold(label, seg.value) {
+				return values, false
+			}
+			continue
+		case paramSegment:
+			if label == "" {
+				return values, false
+			}
+			if seg.matcher != nil {
+				if _, ok := seg.matcher(label); !ok {
+					return values, false
+				}
+			}
+		}
+		if seg.value != "" {
+			values = append(values, label)
+		}
+	}
+	return values, true
+}
+
+// nextHostLabel return the label of host start at pos, and the start of the next label
+func nextHostLabel(host string, pos int) (string, int) {
+	i := strings.IndexByte(host[pos:], '.')
+	if i < 0 {
+		return host[pos:], len(host) + 1
+	}
+	return host[pos : pos+i], pos + i + 1
+}
+
+// Host mount mid (Router or Gor) for the requests whose host match pattern, like `api.example.com`,
+// `:tenant.example.com` or `api.*`
+//
+// the named labels are set into Req.Params, the request which do not match any route of mid
+// fallthrough to the routes registered after
+func (r *Route) Host(pattern string, mid Middleware) *RouteEntry {
+	parent := &route{
+		method:    "ALL",
+		matchType: preMatch,
+		routePath: "/",
+		host:      parseHostPattern(pattern),
+	}
+	if app, ok := mid.(*Gor); ok {
+		parent.children = []*route{newMountRoute("/", app)}
+	} else {
+		parent.children = mid.handler("/")
+		parent.notFound = mid.root().notFoundHandler
+	}
+	return r.addRoute(parent)
+}
+
+// hostOf return the host pattern of the leaf, patterns of nested Host are joined by `,`
+func (l *leaf) hostOf() string {
+	var hosts []string
+	for _, v := range l.hosts {
+		hosts = append(hosts, v.pattern)
+	}
+	return strings.Join(hosts, ",")
+}
+
+// matchHosts report whether m.host match all the host patterns of l, the values are appended to m.values
+func (m *matcher) matchHosts(l *leaf) bool {
+	for _, h := range l.hosts {
+		var ok bool
+		if m.values, ok = h.match(m.host, m.values); !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package gor
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostPattern_match(t *testing.T) {
+	as := assert.New(t)
+
+	for _, v := range []struct {
+		pattern string
+		host    string
+		values  []string
+		matched bool
+	}{
+		{"api.example.com", "api.example.com", nil, true},
+		{"api.example.com", "API.Example.com", nil, true},
+		{"api.example.com", "www.example.com", nil, false},
+		{"api.example.com", "example.com", nil, false},
+		{":tenant.example.com", "foo.example.com", []string{"foo"}, true},
+		{":tenant.example.com", "a.foo.example.com", nil, false},
+		{":id<int>.example.com", "1.example.com", []string{"1"}, true},
+		{":id<int>.example.com", "a.example.com", nil, false},
+		{"api.*", "api.example.com", nil, true},
+		{"api.*", "api.a.example.com", nil, true},
+		{"api.*", "api", nil, false},
+		{"*sub.example.com", "a.b.example.com", []string{"a.b"}, true},
+		{"*.example.com", "example.com", nil, false},
+	} {
+		values, matched := parseHostPattern(v.pattern).match(v.host, nil)
+		as.Equal(v.matched, matched, v.pattern+" "+v.host)
+		if matched {
+			as.Equal(v.values, values, v.pattern+" "+v.host)
+		}
+	}
+
+	as.Panics(func() { parseHostPattern("") })
+	as.Panics(func() { parseHostPattern("api..com") })
+	as.Panics(func() { parseHostPattern("a.*.com") })
+	as.Panics(func() { parseHostPattern("*.example.*") })
+	as.Panics(func() { parseHostPattern(":tenant?.example.com") })
+	as.Panics(func() { parseHostPattern(":id<unknown>.example.com") })
+}
+
+func TestHost(t *testing.T) {
+	app, ts, e, as := newTestServer(t)
+	defer ts.Close()
+
+	api := NewRouter()
+	api.Get("/users", func(req *Req, res *Res) { res.Send("api users") })
+
+	tenant := NewGor()
+	tenant.Use(func(req *Req, res *Res, next Next) {
+		res.AddHeader("X-Tenant", req.Params["tenant"])
+		next()
+	})
+	tenant.Get("/users/:id", func(req *Req, res *Res) { res.JSON(req.Params) })
+
+	app.Host("api.*", api)
+	app.Host(":tenant.example.com", tenant)
+	app.Get("/users", func(req *Req, res *Res) { res.Send("default users") })
+
+	e.GET("/users").WithHeader("Host", "api.example.com").Expect().Status(http.StatusOK).Text().Equal("api users")
+	e.GET("/users").WithHeader("Host", "api.localhost:8080").Expect().Status(http.StatusOK).Text().Equal("api users")
+	e.GET("/users").WithHeader("Host", "www.example.org").Expect().Status(http.StatusOK).Text().Equal("default users")
+	e.GET("/users").Expect().Status(http.StatusOK).Text().Equal("default users")
+
+	r := e.GET("/users/1").WithHeader("Host", "foo.example.com").Expect().Status(http.StatusOK)
+	r.JSON().Equal(map[string]string{"tenant": "foo", "id": "1"})
+	r.Header("X-Tenant").Equal("foo")
+	e.GET("/users/1").Expect().Status(http.StatusNotFound)
+	e.POST("/users").WithHeader("Host", "foo.example.com").Expect().Status(http.StatusMethodNotAllowed).Header("Allow").Equal("GET, HEAD, OPTIONS")
+
+	routes := app.Routes()
+	as.Equal("api.*", routes[0].Host)
+	as.Equal("/users", routes[0].Pattern)
+	// the routes of sub application are flattened with the host
+	as.Equal(":tenant.example.com", routes[1].Host)
+	as.Equal("/", routes[1].Pattern)
+	as.Equal("use", routes[1].Match)
+	as.Equal(":tenant.example.com", routes[2].Host)
+	as.Equal("/users/:id", routes[2].Pattern)
+	as.Equal([]string{routes[1].Handler}, routes[2].Middleware)
+	as.Equal("", routes[3].Host)
+	as.Len(routes[3].Middleware, 0)
+	as.Len(app.Conflicts(), 0)
+}
@@ -1,6 +1,7 @@
 package gor
 
 import (
+	"io"
 	"net/http"
 )
 
@@ -8,6 +9,21 @@ type appInterface interface {
 	SetRenderDir(dir string)
 	SetStaticPath(path string)
 	Static(dir string)
+	SetMethodNotAllowedHandler(h HandlerFunc)
+	SetOptionsHandler(h HandlerFunc)
+	SetErrorHandler(h func(err error, req *Req, res *Res))
+	SetMode(mode Mode)
+	SetBodyLimit(limit BodyLimit)
+	SetImplicitHead(enable bool)
+	SetStrictSlash(strict bool)
+	SetCaseInsensitive(enable bool)
+	SetCleanPath(enable bool)
+	SetRedirectPath(enable bool)
+	SetConflictPolicy(policy ConflictPolicy)
+	SetLogger(logger Logger)
+	Conflicts() []RouteConflict
+	OnMount(f func(mountPath string))
+	MountPath() string
 }
 
 type resInterface interface {
@@ -20,38 +36,54 @@ type resInterface interface {
 	Redirect(path string)
 	AddHeader(key, val string)
 	SetCookie(key, val string, option ...Cookie)
-	Error(v string)
+	Error(v interface{})
 	End()
 }
 
 type reqInterface interface {
 	AddContext(key, val interface{})
 	GetContext(key interface{}) interface{}
+	Body() (*BodyData, error)
+	BodyReader() io.ReadCloser
+	File(name string) (*File, error)
+	Files(name string) ([]*File, error)
+	MultipartReader() (*MultipartReader, error)
 	BindJSON(v interface{}) error
+	ParamValue(key string) interface{}
+	AllowedMethods() []string
+	CorrelationID() string
 }
 
 type normalMethod interface {
-	Get(pattern string, h HandlerFunc)
-	Head(pattern string, h HandlerFunc)
-	Post(pattern string, h HandlerFunc)
-	Put(pattern string, h HandlerFunc)
-	Patch(pattern string, h HandlerFunc)
-	Delete(pattern string, h HandlerFunc)
-	Connect(pattern string, h HandlerFunc)
-	Options(pattern string, h HandlerFunc)
-	Trace(pattern string, h HandlerFunc)
+	Get(pattern string, hs ...interface{}) *RouteEntry
+	Head(pattern string, hs ...interface{}) *RouteEntry
+	Post(pattern string, hs ...interface{}) *RouteEntry
+	Put(pattern string, hs ...interface{}) *RouteEntry
+	Patch(pattern string, hs ...interface{}) *RouteEntry
+	Delete(pattern string, hs ...interface{}) *RouteEntry
+	Connect(pattern string, hs ...interface{}) *RouteEntry
+	Options(pattern string, hs ...interface{}) *RouteEntry
+	Trace(pattern string, hs ...interface{}) *RouteEntry
 }
 
 // Middleware mid
 type Middleware interface {
 	handler(pattern string) []*route
+	// root return the Route which own the routes
+	root() *Route
 }
 
 // RouteInterface define Route Interface
 type RouteInterface interface {
 	Use(...interface{})
 	All(...interface{})
-	Group(string, func(group *Router))
+	Group(string, func(group *Router)) *RouteEntry
+	Path(pattern string) *PathRoute
+	Host(pattern string, mid Middleware) *RouteEntry
+	Param(name string, h ParamHandler)
+	SetNotFoundHandler(h HandlerFunc)
+	Routes() []RouteInfo
+	PrintRoutes(w io.Writer) error
 
 	normalMethod
 	Middleware
@@ -62,6 +94,9 @@ var _ appInterface = (*Gor)(nil)
 var _ RouteInterface = (*Gor)(nil)
 
 var _ RouteInterface = (*Route)(nil)
+var _ http.Handler = (*Route)(nil)
+var _ http.Handler = (*Router)(nil)
+var _ http.Handler = HandlerFunc(nil)
 
 var _ resInterface = (*Res)(nil)
 var _ reqInterface = (*Req)(nil)
@@ -0,0 +1,117 @@
+package gor
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// BodyLimit is the limits to parse the request body, zero field is not limited, or the default value is used
+//
+// the body over the limit is responded with 413 Payload Too Large
+type BodyLimit struct {
+	// MaxBytes is the max bytes of body, the body is read by Req.Body and Req.BodyReader with the limit
+	MaxBytes int64
+	// MaxMultipartMemory is the max bytes of multipart form stored in memory, the remaining files are stored in temporary files,
+	// and 413 is responded if the values (non-file parts) are over it, default 32MB
+	MaxMultipartMemory int64
+	// MaxFormFields is the max count of values of url-encoded or multipart form,
+	// the url-encoded form is counted after it is parsed, so it should be used with MaxBytes
+	MaxFormFields int
+	// MaxFiles is the max count of files of multipart form, it is checked while the parts are read
+	MaxFiles int
+	// MaxFileSize is the max bytes of one file of multipart form, it is checked while the file is read
+	MaxFileSize int64
+}
+
+// SetBodyLimit set the limits to parse the request body, the limits of route (RouteEntry.BodyLimit) override it
+func (g *Gor) SetBodyLimit(limit BodyLimit) {
+	g.bodyLimit = limit
+}
+
+// BodyLimit set the limits to parse the request body of the route, the non-zero fields override the limits of Gor,
+// the limits of Group apply to the routes in the group
+func (e *RouteEntry) BodyLimit(limit BodyLimit) *RouteEntry {
+	e.route.bodyLimit = &limit
+	return e
+}
+
+// merge return l with the non-zero fields of o
+func (l BodyLimit) merge(o BodyLimit) BodyLimit {
+	if o.MaxBytes != 0 {
+		l.MaxBytes = o.MaxBytes
+	}
+	if o.MaxMultipartMemory != 0 {
+		l.MaxMultipartMemory = o.MaxMultipartMemory
+	}
+	if o.MaxFormFields != 0 {
+		l.MaxFormFields = o.MaxFormFields
+	}
+	if o.MaxFiles != 0 {
+		l.MaxFiles = o.MaxFiles
+	}
+	if o.MaxFileSize != 0 {
+		l.MaxFileSize = o.MaxFileSize
+	}
+	return l
+}
+
+// bodyLimitOf return the limits of g merged into limit, and then the limits of the handler matched by m
+func (g *Gor) bodyLimitOf(limit BodyLimit, m *matcher) BodyLimit {
+	limit = limit.merge(g.bodyLimit)
+	for _, v := range m.matches {
+		if v.leaf.prefix {
+			continue
+		}
+		for _, mount := range v.leaf.mounts {
+			if mount.bodyLimit != nil {
+				limit = limit.merge(*mount.bodyLimit)
+			}
+		}
+		if v.leaf.route.bodyLimit != nil {
+			limit = limit.merge(*v.leaf.route.bodyLimit)
+		}
+		break
+	}
+	return limit
+}
+
+// limitBody limit the bytes of the body which can be read, it is called once before the body is read,
+// and return 413 error if the Content-Length is over the limit
+func (req *Req) limitBody() error {
+	if req.bodyLimited {
+		return nil
+	}
+	req.bodyLimited = true
+
+	n := req.bodyLimit.MaxBytes
+	if n <= 0 || req.r.Body == nil || req.r.Body == http.NoBody {
+		return nil
+	}
+	req.r.Body = http.MaxBytesReader(nil, req.r.Body, n)
+	if req.r.ContentLength > n {
+		return PayloadTooLargeError().WithCause(fmt.Errorf("content length %d is over the limit %d", req.r.ContentLength, n))
+	}
+	return nil
+}
+
+// bodyError return 413 HTTPError if err is caused by the body over the limit, otherwise err
+func bodyError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return PayloadTooLargeError().WithCause(err)
+	}
+	return err
+}
+
+// checkForm return 413 error if the parsed url-encoded form of r is over the limits
+func (l BodyLimit) checkForm(r *http.Request) error {
+	fields := 0
+	for _, v := range r.PostForm {
+		fields += len(v)
+	}
+	if l.MaxFormFields > 0 && fields > l.MaxFormFields {
+		return PayloadTooLargeError("too many form fields")
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package gor
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestBodyLimit(t *testing.T) {
+	app, ts, e, as := newTestServer(t)
+	defer ts.Close()
+
+	body := func(req *Req, res *Res) error {
+		body, err := req.Body()
+		if err != nil {
+			return err
+		}
+		res.JSON(*body)
+		return nil
+	}
+	raw := func(req *Req, res *Res) error {
+		b, err := ioutil.ReadAll(req.BodyReader())
+		if err != nil {
+			return err
+		}
+		res.Send(string(b))
+		return nil
+	}
+
+	app.SetBodyLimit(BodyLimit{MaxBytes: 16, MaxFormFields: 2, MaxFiles: 1})
+	app.Post("/body", body)
+	app.Post("/raw", raw)
+	app.Post("/bind", func(req *Req, res *Res) error {
+		var v map[string]string
+		if err := req.BindJSON(&v); err != nil {
+			return err
+		}
+		res.JSON(v)
+		return nil
+	})
+	app.Post("/http", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := ioutil.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.Write([]byte("read"))
+	}))
+	app.Post("/big", body).BodyLimit(BodyLimit{MaxBytes: 1024, MaxFileSize: 4})
+	app.Post("/memory", body).BodyLimit(BodyLimit{MaxBytes: 1 << 21, MaxFormFields: 3, MaxMultipartMemory: 10})
+	app.Group("/group", func(group *Router) {
+		group.Post("/body", body)
+	}).BodyLimit(BodyLimit{MaxBytes: 8})
+
+	e.POST("/body").WithJSON(map[string]string{"s": "d"}).Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"JSON": map[string]string{"s": "d"}, "FormURLEncoded": nil, "FormData": nil, "Files": nil})
+	e.POST("/body").WithJSON(map[string]string{"s": "0123456789"}).Expect().Status(http.StatusRequestEntityTooLarge).Text().Equal("Request Entity Too Large")
+	e.POST("/raw").WithText("0123456789abcdefg").Expect().Status(http.StatusRequestEntityTooLarge)
+	e.POST("/big").WithJSON(map[string]string{"s": "0123456789"}).Expect().Status(http.StatusOK)
+	e.POST("/bind").WithJSON(map[string]string{"s": "d"}).Expect().Status(http.StatusOK).JSON().Equal(map[string]string{"s": "d"})
+	e.POST("/bind").WithJSON(map[string]string{"s": "0123456789abcdefghijklmn"}).Expect().Status(http.StatusRequestEntityTooLarge)
+	e.POST("/http").WithText("0123456789").Expect().Status(http.StatusOK).Text().Equal("read")
+	e.POST("/http").WithText("0123456789abcdefg").Expect().Status(http.StatusRequestEntityTooLarge).Text().Equal("Request Entity Too Large")
+	e.POST("/group/body").WithJSON(map[string]string{"s": "d"}).Expect().Status(http.StatusRequestEntityTooLarge)
+
+	e.POST("/body").WithForm(map[string]string{"a": "1", "b": "2"}).Expect().Status(http.StatusOK)
+	e.POST("/body").WithForm(map[string]string{"a": "1", "b": "2", "c": "3"}).Expect().Status(http.StatusRequestEntityTooLarge).Text().Equal("too many form fields")
+	e.POST("/big").WithMultipart().WithFileBytes("f", "a.txt", []byte("abcd")).Expect().Status(http.StatusOK)
+	e.POST("/big").WithMultipart().WithFileBytes("f", "a.txt", []byte("abcde")).Expect().Status(http.StatusRequestEntityTooLarge).Text().Equal("file is too large: a.txt")
+	e.POST("/big").WithMultipart().WithFileBytes("f", "a.txt", []byte("a")).WithFileBytes("g", "b.txt", []byte("b")).
+		Expect().Status(http.StatusRequestEntityTooLarge).Text().Equal("too many files")
+	e.POST("/memory").WithMultipart().WithFormField("a", "01234").WithFormField("b", "56789").Expect().Status(http.StatusOK)
+	e.POST("/memory").WithMultipart().WithFormField("a", "01234").WithFormField("b", "56789").WithFormField("c", "x").
+		Expect().Status(http.StatusRequestEntityTooLarge).Text().Equal("form field is too large: c")
+	e.POST("/memory").WithMultipart().WithFormField("a", strings.Repeat("a", 1<<20)).
+		Expect().Status(http.StatusRequestEntityTooLarge).Text().Equal("form field is too large: a")
+
+	// the body without Content-Length is limited when it is read
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/raw", strings.NewReader("0123456789abcdefg"))
+	r.ContentLength = -1
+	app.ServeHTTP(w, r)
+	as.Equal(http.StatusRequestEntityTooLarge, w.Code)
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/raw", strings.NewReader("0123456789"))
+	r.ContentLength = -1
+	app.ServeHTTP(w, r)
+	as.Equal(http.StatusOK, w.Code)
+	as.Equal("0123456789", w.Body.String())
+
+	for _, path := range []string{"/bind", "/http"} {
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"s": "0123456789abcdefghijklmn"}`))
+		r.ContentLength = -1
+		app.ServeHTTP(w, r)
+		as.Equal(http.StatusRequestEntityTooLarge, w.Code, path)
+	}
+}
+
+func TestBodyLimit_multipart_read(t *testing.T) {
+	app, ts, _, as := newTestServer(t)
+	defer ts.Close()
+
+	app.Post("/", func(req *Req, res *Res) error {
+		_, err := req.Body()
+		return err
+	}).BodyLimit(BodyLimit{MaxFiles: 1, MaxFileSize: 4})
+
+	for _, v := range []struct {
+		files []string
+		msg   string
+	}{
+		{[]string{"abcd", "e", strings.Repeat("f", 1<<20)}, "too many files"},
+		{[]string{"abcde" + strings.Repeat("f", 1<<20)}, "file is too large: 0.txt"},
+	} {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		for i, f := range v.files {
+			w, _ := mw.CreateFormFile("f", strconv.Itoa(i)+".txt")
+			w.Write([]byte(f))
+		}
+		mw.Close()
+
+		// the rest of body is not read after the limit is exceeded
+		body := bytes.NewReader(buf.Bytes())
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		app.ServeHTTP(w, r)
+		as.Equal(http.StatusRequestEntityTooLarge, w.Code)
+		as.Equal(v.msg, w.Body.String())
+		as.True(body.Len() > 1<<19)
+	}
+}
@@ -1,16 +1,16 @@
 package middlerware
 
 import (
-	"fmt"
+	"runtime/debug"
 
 	"github.com/Chyroc/gor"
 )
 
-// Recover default gor Recover middleware
+// Recover default gor Recover middleware, the panic is responded as *gor.PanicError with the stack trace
 var Recover = func(req *gor.Req, res *gor.Res, next gor.Next) {
 	defer func() {
 		if rev := recover(); rev != nil {
-			res.Error(fmt.Sprintf("panic: %s\n", rev))
+			res.Error(&gor.PanicError{Value: rev, Stack: debug.Stack()})
 		}
 	}()
 	next()
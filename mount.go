@@ -0,0 +1,117 @@
+package gor
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// mount mount app as sub application at pattern
+func (r *Route) mount(pattern string, app *Gor) *RouteEntry {
+	return r.addRoute(newMountRoute(pattern, app))
+}
+
+// newMountRoute return the prefix route which serve the request by app
+func newMountRoute(pattern string, app *Gor) *route {
+	r := newHandlerRoute("ALL", pattern, preMatch, handler{handlerFuncNext: app.serveMounted, label: httpHandlerName(app)})
+	r.app = app
+	return r
+}
+
+// OnMount add hook which is called when g is mounted as sub application, with the mount path,
+// it is called again with the new mount path when the parent application of g is mounted
+func (g *Gor) OnMount(f func(mountPath string)) {
+	g.onMount = append(g.onMount, f)
+}
+
+// MountPath return the pattern which g is mounted at, including the patterns of the parent applications,
+// empty if g is not mounted
+func (g *Gor) MountPath() string {
+	if g.parent == nil {
+		return g.mountPattern
+	}
+	return joinRoutePath(g.parent.MountPath(), g.mountPattern)
+}
+
+func (g *Gor) mounted(parent *Gor, pattern string) {
+	g.parent = parent
+	g.mountPattern = pattern
+	parent.children = append(parent.children, g)
+	g.callOnMount()
+}
+
+// callOnMount call the OnMount hooks of g and the sub applications of g with their mount paths
+func (g *Gor) callOnMount() {
+	for _, f := range g.onMount {
+		f(g.MountPath())
+	}
+	for _, v := range g.children {
+		v.callOnMount()
+	}
+}
+
+// serveMounted serve the request with the routes and settings of g, the path matched by the mount route is stripped,
+// the error which is not handled by the routes of g is responded by the error handler of g if it is set,
+// and the request which is not handled by g is passed to the next handler of parent
+func (g *Gor) serveMounted(req *Req, res *Res, next Next) {
+	mountPath, path, baseURL, params, mountParams, matched, render, app := req.MountPath, req.Path, req.BaseURL, req.Params, req.mountParams, req.matched, res.render, res.app
+	bodyLimit := req.bodyLimit
+
+	i := req.matchedPrefix()
+	req.MountPath = mountPath + strings.TrimSuffix(path[:i], "/")
+	req.Path = path[i:]
+	if req.Path == "" {
+		req.Path = "/"
+	}
+	req.mountParams = make(map[string]string, len(params))
+	for k, v := range params {
+		req.mountParams[k] = v
+	}
+	res.render, res.app = g.newRender(), g
+
+	if !g.serveStatic(req, res) {
+		g.handle(req, res)
+	}
+	if g.notFoundHandler != nil {
+		handleNotFound(g.notFoundHandler, req, res)
+	}
+	if g.errorHandler != nil && req.err != nil && !res.exit {
+		g.errorHandler(req.err, req, res)
+	}
+
+	req.MountPath, req.Path, req.BaseURL, req.Params, req.mountParams, req.matched, res.render, res.app = mountPath, path, baseURL, params, mountParams, matched, render, app
+	req.bodyLimit = bodyLimit
+	if err := req.err; err != nil && !res.exit {
+		next(err)
+	} else if !res.exit {
+		next()
+	}
+}
+
+// serveStatic serve the static file if req.Path start with the static path, and report whether it is served
+//
+// the request of file which does not exist is not served, it is routed like other requests
+func (g *Gor) serveStatic(req *Req, res *Res) bool {
+	staticPath := g.staticFilePath
+	if staticPath == "" {
+		staticPath = "/static"
+	}
+	if req.Method != http.MethodGet || g.staticFielDir == "" || !strings.HasPrefix(req.Path, staticPath) {
+		return false
+	}
+	f, err := http.Dir(g.staticFielDir).Open(path.Clean("/" + strings.TrimPrefix(req.Path, staticPath)))
+	if err != nil {
+		return false
+	}
+	f.Close()
+
+	r := req.r.WithContext(req.context)
+	u := *r.URL
+	u.Path = req.Path
+	u.RawPath = ""
+	r.URL = &u
+
+	res.exit = true
+	http.StripPrefix(staticPath, http.FileServer(http.Dir(g.staticFielDir))).ServeHTTP(res.w, r)
+	return true
+}
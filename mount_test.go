@@ -0,0 +1,91 @@
+package gor
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMount(t *testing.T) {
+	app, ts, e, as := newTestServer(t)
+	defer ts.Close()
+
+	var mountPaths []string
+	admin := NewGor()
+	admin.SetMethodNotAllowedHandler(func(req *Req, res *Res) {
+		res.Status(http.StatusMethodNotAllowed).Send("admin not allowed")
+	})
+	admin.OnMount(func(mountPath string) { mountPaths = append(mountPaths, mountPath) })
+	admin.Get("/", func(req *Req, res *Res) { res.Send("admin " + req.Path) })
+	admin.Get("/users/:id", func(req *Req, res *Res) {
+		res.JSON(map[string]string{"mount": req.MountPath, "path": req.Path, "base": req.BaseURL, "org": req.Params["org"], "id": req.Params["id"]})
+	}).Name("user")
+
+	router := NewRouter()
+	router.Use("/admin", admin)
+	as.Len(mountPaths, 0)
+	app.Use("/orgs/:org", router)
+	app.Get("/orgs/:org/admin/users/:id", func(req *Req, res *Res) { res.Send("parent") })
+	app.Get("/orgs/:org/admin/fallthrough", func(req *Req, res *Res) { res.Send("parent " + req.Path) })
+
+	as.Equal([]string{"/orgs/:org/admin"}, mountPaths)
+	as.Equal("/orgs/:org/admin", admin.MountPath())
+	u, err := admin.URLFor("user", map[string]string{"org": "acme", "id": "2"}, nil)
+	as.Nil(err)
+	as.Equal("/orgs/acme/admin/users/2", u)
+
+	e.GET("/orgs/acme/admin/users/1").Expect().Status(http.StatusOK).JSON().Equal(map[string]string{
+		"mount": "/orgs/acme/admin", "path": "/users/1", "base": "/orgs/acme/admin/users/1", "org": "acme", "id": "1",
+	})
+	e.GET("/orgs/acme/admin").Expect().Status(http.StatusOK).Text().Equal("admin /")
+	e.GET("/orgs/acme/admin/fallthrough").Expect().Status(http.StatusOK).Text().Equal("parent /orgs/acme/admin/fallthrough")
+	e.POST("/orgs/acme/admin/users/1").Expect().Status(http.StatusMethodNotAllowed).Text().Equal("admin not allowed")
+	e.GET("/orgs/acme/other").Expect().Status(http.StatusNotFound)
+
+	blog := NewGor()
+	blog.SetRenderDir("testdata/url")
+	blog.Get("/users/:id", func(req *Req, res *Res) {}).Name("user.show")
+	blog.Get("/link/:id", func(req *Req, res *Res) { res.HTML("link", map[string]string{"ID": req.Params["id"]}) })
+	app.Use("/blog", blog)
+	e.GET("/blog/link/1").Expect().Status(http.StatusOK).Body().Equal(`<a href="/blog/users/1?tab=info">1</a>`)
+}
+
+func TestMount_error_handler(t *testing.T) {
+	app, ts, e, _ := newTestServer(t)
+	defer ts.Close()
+
+	api := NewGor()
+	api.SetErrorHandler(func(err error, req *Req, res *Res) {
+		res.Status(http.StatusTeapot).Send("api " + err.Error())
+	})
+	api.Get("/fail", func(req *Req, res *Res) error { return errors.New("fail") })
+	web := NewGor()
+	web.Get("/fail", func(req *Req, res *Res) error { return errors.New("fail") })
+
+	app.Use("/api", api)
+	app.Use("/web", web)
+	app.SetErrorHandler(func(err error, req *Req, res *Res) {
+		res.Status(http.StatusBadGateway).Send("app " + err.Error())
+	})
+
+	e.GET("/api/fail").Expect().Status(http.StatusTeapot).Text().Equal("api fail")
+	e.GET("/web/fail").Expect().Status(http.StatusBadGateway).Text().Equal("app fail")
+}
+
+func TestMount_nested_on_mount(t *testing.T) {
+	as := assert.New(t)
+
+	var mountPaths []string
+	users := NewGor()
+	users.OnMount(func(mountPath string) { mountPaths = append(mountPaths, mountPath) })
+	api := NewGor()
+	api.Use("/users", users)
+	as.Equal([]string{"/users"}, mountPaths)
+
+	app := NewGor()
+	app.Use("/api", api)
+	as.Equal([]string{"/users", "/api/users"}, mountPaths)
+	as.Equal("/api/users", users.MountPath())
+}
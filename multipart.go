@@ -0,0 +1,263 @@
+package gor
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// File is the uploaded file of multipart form
+type File struct {
+	// Field is the form field name of the file
+	Field string
+	// Filename is the file name given by the client, it is unsafe to be used as path, use SafeFilename or SaveTo
+	Filename string
+	Size     int64
+	// ContentType is the Content-Type declared by the client, use SniffContentType to detect it by the content
+	ContentType string
+	Header      textproto.MIMEHeader
+
+	open func() (multipart.File, error)
+}
+
+// Open open the content of the file
+func (f *File) Open() (multipart.File, error) {
+	return f.open()
+}
+
+// SniffContentType detect the Content-Type by the first 512 bytes of the content, like http.DetectContentType
+func (f *File) SniffContentType() (string, error) {
+	r, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	b := make([]byte, 512)
+	n, err := io.ReadFull(r, b)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	return http.DetectContentType(b[:n]), nil
+}
+
+// SaveTo save the file to path, and return the path saved
+//
+// if path is an existing directory, the file is saved in it with the name SafeFilename(f.Filename),
+// the existing file is not overwritten, the error of os.ErrExist is returned
+func (f *File) SaveTo(path string) (string, error) {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		path = filepath.Join(path, SafeFilename(f.Filename))
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", err
+	}
+	if _, err = io.Copy(dst, src); err != nil {
+		dst.Close()
+		return "", err
+	}
+	return path, dst.Close()
+}
+
+// SafeFilename return the base name of filename, which can be used as the name of file safely
+//
+// the directory (by `/` or `\`) is removed, the characters other than letters, digits, `.`, `-` and `_` are replaced with `_`,
+// the leading `.` is removed, the name is truncated to the last 255 bytes on the boundary of character,
+// and `file` is returned if the result is empty
+func SafeFilename(filename string) string {
+	if i := strings.LastIndexAny(filename, `/\`); i >= 0 {
+		filename = filename[i+1:]
+	}
+
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, filename)
+	name = strings.TrimLeft(name, ".")
+	if len(name) > 255 {
+		i := len(name) - 255
+		for i < len(name) && !utf8.RuneStart(name[i]) {
+			i++
+		}
+		name = name[i:]
+	}
+	if name == "" {
+		return "file"
+	}
+	return name
+}
+
+// File return the first file of the multipart form field name, it is read by Body
+func (req *Req) File(name string) (*File, error) {
+	files, err := req.Files(name)
+	if err != nil {
+		return nil, err
+	}
+	return files[0], nil
+}
+
+// Files return the files of the multipart form field name, it is read by Body
+func (req *Req) Files(name string) ([]*File, error) {
+	body, err := req.Body()
+	if err != nil {
+		return nil, err
+	}
+	if len(body.Files[name]) == 0 {
+		return nil, BadRequestError("missing file: " + name).WithCause(http.ErrMissingFile)
+	}
+	return body.Files[name], nil
+}
+
+// MultipartReader is the streaming iterator of the files of multipart body
+type MultipartReader struct {
+	r      *multipart.Reader
+	req    *Req
+	files  int
+	fields int
+	// memory is the remaining bytes of values and files can be stored in memory
+	memory int64
+
+	// Value is the values of the non-file parts, which are read by Next
+	Value map[string][]string
+}
+
+// MultipartReader return the streaming iterator of the multipart body, the body is limited by BodyLimit,
+// but it is not parsed, so Body and File can not be used with it
+func (req *Req) MultipartReader() (*MultipartReader, error) {
+	if err := req.limitBody(); err != nil {
+		return nil, err
+	}
+	r, err := req.r.MultipartReader()
+	if err != nil {
+		return nil, BadRequestError(err.Error()).WithCause(err)
+	}
+	memory := req.bodyLimit.MaxMultipartMemory
+	if memory <= 0 {
+		memory = defaultMultipartMemory
+	}
+	return &MultipartReader{r: r, req: req, memory: memory, Value: make(map[string][]string)}, nil
+}
+
+// Next read the next file, io.EOF is returned when there is no more file
+//
+// the content of file is stored in memory, it is stored in temporary file if the files read are larger than BodyLimit.MaxMultipartMemory,
+// the temporary files are removed when the request is finished,
+// and the values of non-file parts are stored in Value, 413 error is returned if they are larger than the remaining memory
+func (m *MultipartReader) Next() (*File, error) {
+	limit := m.req.bodyLimit
+	for {
+		p, err := m.r.NextPart()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.FileName() == "" {
+			m.fields++
+			if limit.MaxFormFields > 0 && m.fields > limit.MaxFormFields {
+				return nil, PayloadTooLargeError("too many form fields")
+			}
+			// the values are stored in memory, they can not be larger than the remaining memory
+			b, err := ioutil.ReadAll(io.LimitReader(p, m.memory+1))
+			if err != nil {
+				return nil, err
+			}
+			if int64(len(b)) > m.memory {
+				return nil, PayloadTooLargeError("form field is too large: " + p.FormName())
+			}
+			m.memory -= int64(len(b))
+			m.Value[p.FormName()] = append(m.Value[p.FormName()], string(b))
+			continue
+		}
+
+		m.files++
+		if limit.MaxFiles > 0 && m.files > limit.MaxFiles {
+			return nil, PayloadTooLargeError("too many files")
+		}
+		return m.readFile(p)
+	}
+}
+
+func (m *MultipartReader) readFile(p *multipart.Part) (*File, error) {
+	limit := m.req.bodyLimit
+	f := &File{Field: p.FormName(), Filename: p.FileName(), ContentType: p.Header.Get("Content-Type"), Header: p.Header}
+	tooLarge := PayloadTooLargeError("file is too large: " + f.Filename)
+
+	var r io.Reader = p
+	if limit.MaxFileSize > 0 {
+		r = io.LimitReader(p, limit.MaxFileSize+1)
+	}
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, r, m.memory+1)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if limit.MaxFileSize > 0 && n > limit.MaxFileSize {
+		return nil, tooLarge
+	}
+	if n <= m.memory {
+		m.memory -= n
+		data := buf.Bytes()
+		f.Size = n
+		f.open = func() (multipart.File, error) {
+			return memoryFile{bytes.NewReader(data)}, nil
+		}
+		return f, nil
+	}
+
+	// the file is larger than the remaining memory, store it in temporary file
+	tmp, err := ioutil.TempFile("", "gor-multipart-")
+	if err != nil {
+		return nil, err
+	}
+	m.req.tempFiles = append(m.req.tempFiles, tmp.Name())
+	n, err = io.Copy(tmp, io.MultiReader(&buf, r))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if limit.MaxFileSize > 0 && n > limit.MaxFileSize {
+		return nil, tooLarge
+	}
+	name := tmp.Name()
+	f.Size = n
+	f.open = func() (multipart.File, error) {
+		return os.Open(name)
+	}
+	return f, nil
+}
+
+// removeTempFiles remove the temporary files of multipart body
+func (req *Req) removeTempFiles() {
+	for _, v := range req.tempFiles {
+		os.Remove(v)
+	}
+}
+
+// memoryFile is the multipart.File stored in memory
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
@@ -0,0 +1,190 @@
+package gor
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n0000")
+
+type multipartPart struct {
+	field, filename, contentType string
+	content                      []byte
+}
+
+func newMultipartRequest(t *testing.T, path string, parts ...multipartPart) *http.Request {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, v := range parts {
+		h := make(textproto.MIMEHeader)
+		if v.filename == "" {
+			h.Set("Content-Disposition", `form-data; name="`+v.field+`"`)
+		} else {
+			h.Set("Content-Disposition", `form-data; name="`+v.field+`"; filename="`+v.filename+`"`)
+			h.Set("Content-Type", v.contentType)
+		}
+		pw, err := w.CreatePart(h)
+		assert.Nil(t, err)
+		pw.Write(v.content)
+	}
+	assert.Nil(t, w.Close())
+
+	r := httptest.NewRequest(http.MethodPost, path, &body)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+	return r
+}
+
+func TestSafeFilename(t *testing.T) {
+	as := assert.New(t)
+
+	for _, v := range [][2]string{
+		{"a.txt", "a.txt"},
+		{"../../etc/passwd", "passwd"},
+		{`C:\Users\a\b.png`, "b.png"},
+		{"..", "file"},
+		{".htaccess", "htaccess"},
+		{"a b;c.txt", "a_b_c.txt"},
+		{"文件.txt", "文件.txt"},
+		{"", "file"},
+	} {
+		as.Equal(v[1], SafeFilename(v[0]), v[0])
+	}
+
+	name := SafeFilename(strings.Repeat("文", 100) + ".txt")
+	as.True(utf8.ValidString(name))
+	as.Equal(strings.Repeat("文", 83)+".txt", name)
+}
+
+func TestReq_File(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "gor-test-")
+	as.Nil(err)
+	defer os.RemoveAll(dir)
+
+	app := NewGor()
+	app.Post("/upload", func(req *Req, res *Res) error {
+		avatar, err := req.File("avatar")
+		if err != nil {
+			return err
+		}
+		as.Equal("avatar", avatar.Field)
+		as.Equal(".my avatar;.png", avatar.Filename)
+		as.Equal(int64(len(pngHeader)), avatar.Size)
+		as.Equal("text/plain", avatar.ContentType)
+		sniffed, err := avatar.SniffContentType()
+		as.Nil(err)
+		as.Equal("image/png", sniffed)
+
+		path, err := avatar.SaveTo(dir)
+		as.Nil(err)
+		as.Equal(filepath.Join(dir, "my_avatar_.png"), path)
+		b, err := ioutil.ReadFile(path)
+		as.Nil(err)
+		as.Equal(pngHeader, b)
+		_, err = avatar.SaveTo(dir)
+		as.True(errors.Is(err, os.ErrExist))
+
+		docs, err := req.Files("docs")
+		if err != nil {
+			return err
+		}
+		as.Len(docs, 2)
+		as.Equal("b.txt", docs[1].Filename)
+
+		body, err := req.Body()
+		as.Nil(err)
+		as.Equal([]string{"1"}, body.FormData["name"])
+		res.Send("ok")
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, newMultipartRequest(t, "/upload",
+		multipartPart{field: "name", content: []byte("1")},
+		multipartPart{field: "avatar", filename: ".my avatar;.png", contentType: "text/plain", content: pngHeader},
+		multipartPart{field: "docs", filename: "a.txt", contentType: "text/plain", content: []byte("a")},
+		multipartPart{field: "docs", filename: "b.txt", contentType: "text/plain", content: []byte("b")},
+	))
+	as.Equal(http.StatusOK, w.Code)
+	as.Equal("ok", w.Body.String())
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, newMultipartRequest(t, "/upload", multipartPart{field: "name", content: []byte("1")}))
+	as.Equal(http.StatusBadRequest, w.Code)
+	as.Equal("missing file: avatar", w.Body.String())
+
+	_, err = (&Req{r: httptest.NewRequest(http.MethodPost, "/", nil)}).File("avatar")
+	as.True(errors.Is(err, http.ErrMissingFile))
+}
+
+func TestReq_MultipartReader(t *testing.T) {
+	as := assert.New(t)
+
+	var tempFiles []string
+	app := NewGor()
+	app.SetBodyLimit(BodyLimit{MaxMultipartMemory: 4, MaxFiles: 2})
+	app.Post("/upload", func(req *Req, res *Res) error {
+		mr, err := req.MultipartReader()
+		if err != nil {
+			return err
+		}
+		var names []string
+		for {
+			f, err := mr.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+
+			r, err := f.Open()
+			as.Nil(err)
+			b, err := ioutil.ReadAll(r)
+			r.Close()
+			as.Nil(err)
+			as.Equal(f.Size, int64(len(b)))
+			names = append(names, f.Filename+":"+string(b))
+		}
+		tempFiles = req.tempFiles
+		res.JSON(map[string]interface{}{"files": names, "values": mr.Value})
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, newMultipartRequest(t, "/upload",
+		multipartPart{field: "small", filename: "a.txt", contentType: "text/plain", content: []byte("abc")},
+		multipartPart{field: "name", content: []byte("1")},
+		multipartPart{field: "large", filename: "b.txt", contentType: "text/plain", content: []byte("0123456789")},
+	))
+	as.Equal(http.StatusOK, w.Code)
+	as.JSONEq(`{"files": ["a.txt:abc", "b.txt:0123456789"], "values": {"name": ["1"]}}`, w.Body.String())
+	as.Len(tempFiles, 1)
+	_, err := os.Stat(tempFiles[0])
+	as.True(os.IsNotExist(err))
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, newMultipartRequest(t, "/upload",
+		multipartPart{field: "f", filename: "a.txt", contentType: "text/plain", content: []byte("a")},
+		multipartPart{field: "f", filename: "b.txt", contentType: "text/plain", content: []byte("b")},
+		multipartPart{field: "f", filename: "c.txt", contentType: "text/plain", content: []byte("c")},
+	))
+	as.Equal(http.StatusRequestEntityTooLarge, w.Code)
+	as.Equal("too many files", w.Body.String())
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/upload", nil))
+	as.Equal(http.StatusBadRequest, w.Code)
+}
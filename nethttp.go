@@ -0,0 +1,110 @@
+package gor
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// httpHandler convert http.Handler to HandlerFunc, the handler always end the request
+//
+// the body is limited by BodyLimit.MaxBytes, 413 is responded if the Content-Length is over the limit
+func httpHandler(h http.Handler) HandlerFunc {
+	return func(req *Req, res *Res) {
+		if err := req.limitBody(); err != nil {
+			res.Error(err)
+			return
+		}
+		res.exit = true
+		h.ServeHTTP(res.w, req.httpRequest())
+	}
+}
+
+// httpMiddleware convert func(http.Handler) http.Handler to HandlerFuncNext
+//
+// the request and response writer passed to the next handler by the middleware are used by the later gor handlers
+func httpMiddleware(m func(http.Handler) http.Handler) HandlerFuncNext {
+	return func(req *Req, res *Res, next Next) {
+		if err := req.limitBody(); err != nil {
+			next(err)
+			return
+		}
+		w := res.w
+		m(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			req.setRequest(r)
+			res.w = rw
+			next()
+			res.w = w
+		})).ServeHTTP(res.w, req.httpRequest())
+	}
+}
+
+// httpHandlerName return the func name, or the type name with full package path of h
+func httpHandlerName(h http.Handler) string {
+	t := reflect.TypeOf(h)
+	if t.Kind() == reflect.Func {
+		return funcName(h)
+	}
+
+	ptr := ""
+	if t.Kind() == reflect.Ptr {
+		ptr, t = "*", t.Elem()
+	}
+	if t.PkgPath() == "" || t.Name() == "" {
+		return fmt.Sprintf("%T", h)
+	}
+	return ptr + t.PkgPath() + "." + t.Name()
+}
+
+// httpRequest return the *http.Request with the context of req,
+// and the path matched by the prefix route (Use) is stripped like http.StripPrefix
+func (req *Req) httpRequest() *http.Request {
+	r := req.r.WithContext(req.context)
+	if req.matched == nil || !req.matched.prefix {
+		return r
+	}
+
+	u := *r.URL
+	u.Path = req.Path[req.matchedPrefix():]
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.RawPath = ""
+	r.URL = &u
+	return r
+}
+
+// matchedPrefix return the length of req.Path matched by the matched prefix route
+func (req *Req) matchedPrefix() int {
+	n := len(req.matched.ranks)
+	if n > 0 && req.matched.ranks[n-1] == (segment{kind: catchAllSegment}).rank() {
+		// catch-all match the rest of the path
+		return len(req.Path)
+	}
+	return prefixSegments(req.Path, n)
+}
+
+// setRequest set the context and headers of r to req
+func (req *Req) setRequest(r *http.Request) {
+	req.context = r.Context()
+	req.r = req.r.WithContext(req.context)
+	req.r.Header = r.Header
+	req.Headers = r.Header
+}
+
+// prefixSegments return the length of the first n non-empty segments of path
+func prefixSegments(path string, n int) int {
+	i := 0
+	for ; n > 0 && i < len(path); n-- {
+		for i < len(path) && path[i] == '/' {
+			i++
+		}
+		if j := strings.IndexByte(path[i:], '/'); j >= 0 {
+			i += j
+		} else {
+			i = len(path)
+		}
+	}
+	return i
+}
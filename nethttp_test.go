@@ -0,0 +1,74 @@
+package gor
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+type netHTTPTestHandler struct{}
+
+func (netHTTPTestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("struct " + r.URL.Path))
+}
+
+type netHTTPTestKey struct{}
+
+func TestNetHTTP(t *testing.T) {
+	app, ts, e, as := newTestServer(t)
+	defer ts.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/vars", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("mux " + r.URL.Path + " " + r.URL.RawQuery))
+	})
+
+	auth := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Token") == "" {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), netHTTPTestKey{}, r.Header.Get("X-Token")))
+			r.Header.Set("X-User", "user-"+r.Header.Get("X-Token"))
+			w.Header().Set("X-Auth", "1")
+			h.ServeHTTP(w, r)
+		})
+	}
+
+	app.Use("/debug", mux)
+	app.Use("/struct", netHTTPTestHandler{})
+	app.Get("/func/:id", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("func " + r.URL.Path)) })
+	app.Use("/files/*filepath", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("files " + r.URL.Path)) }))
+	app.Use("/auth", auth)
+	app.Get("/auth/user", func(req *Req, res *Res) {
+		res.Send(req.GetContext(netHTTPTestKey{}).(string) + " " + req.Headers["X-User"][0])
+	})
+	app.Use("/ctx", func(req *Req, res *Res, next Next) {
+		req.AddContext(netHTTPTestKey{}, "gor")
+		next()
+	})
+	app.Get("/ctx", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Context().Value(netHTTPTestKey{}).(string)))
+	})
+
+	e.GET("/debug/vars").WithQuery("a", "1").Expect().Status(http.StatusOK).Text().Equal("mux /vars a=1")
+	e.GET("/debug/not-exist").Expect().Status(http.StatusNotFound).Text().Equal("404 page not found\n")
+	e.GET("/struct/a/b").Expect().Status(http.StatusOK).Text().Equal("struct /a/b")
+	e.GET("/struct").Expect().Status(http.StatusOK).Text().Equal("struct /")
+	e.GET("/func/1").Expect().Status(http.StatusOK).Text().Equal("func /func/1")
+	e.GET("/files/a/b").Expect().Status(http.StatusOK).Text().Equal("files /")
+	e.GET("/auth/user").Expect().Status(http.StatusForbidden).Text().Equal("forbidden\n")
+	r := e.GET("/auth/user").WithHeader("X-Token", "1").Expect().Status(http.StatusOK)
+	r.Text().Equal("1 user-1")
+	r.Header("X-Auth").Equal("1")
+	e.GET("/ctx").Expect().Status(http.StatusOK).Text().Equal("gor")
+
+	routes := app.Routes()
+	as.Equal("*net/http.ServeMux", routes[0].Handler)
+	as.Equal("github.com/Chyroc/gor.netHTTPTestHandler", routes[1].Handler)
+	as.Equal("github.com/Chyroc/gor.TestNetHTTP.func3", routes[2].Handler)
+
+	as.Panics(func() { app.Use("/wrong", &struct{}{}) })
+	as.Panics(func() { app.Get("/wrong", func(w http.ResponseWriter) {}) })
+}
@@ -0,0 +1,164 @@
+package gor
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ParamMatcher check route param value, and return the typed value when it is matched
+//
+// register it by RegisterParamMatcher and use it in pattern like `/users/:id<int>`
+type ParamMatcher func(value string) (interface{}, bool)
+
+var paramMatchers = struct {
+	sync.RWMutex
+	m map[string]ParamMatcher
+}{
+	m: map[string]ParamMatcher{
+		"int":   intParamMatcher,
+		"alpha": stringParamMatcher(regexp.MustCompile(`^[a-zA-Z]+$`)),
+		"alnum": stringParamMatcher(regexp.MustCompile(`^[a-zA-Z0-9]+$`)),
+		"uuid":  stringParamMatcher(regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)),
+	},
+}
+
+// RegisterParamMatcher register a named param matcher, it will override the old one with same name
+//
+// builtin: int, alpha, alnum, uuid
+func RegisterParamMatcher(name string, matcher ParamMatcher) {
+	if matcher == nil {
+		panic("param matcher cannot be nil")
+	}
+
+	paramMatchers.Lock()
+	defer paramMatchers.Unlock()
+	paramMatchers.m[name] = matcher
+}
+
+func getParamMatcher(name string) ParamMatcher {
+	paramMatchers.RLock()
+	defer paramMatchers.RUnlock()
+	return paramMatchers.m[name]
+}
+
+func intParamMatcher(value string) (interface{}, bool) {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, false
+	}
+	return i, true
+}
+
+func stringParamMatcher(reg *regexp.Regexp) ParamMatcher {
+	return func(value string) (interface{}, bool) {
+		if !reg.MatchString(value) {
+			return nil, false
+		}
+		return value, true
+	}
+}
+
+func regexpParamMatcher(expr string) (ParamMatcher, error) {
+	reg, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		return nil, err
+	}
+	return stringParamMatcher(reg), nil
+}
+
+// ParamValue return the typed value of param, which is converted by the ParamMatcher of the matched route
+//
+// the value is string if the param is not constrained by named matcher, and nil if param not exist
+func (req *Req) ParamValue(key string) interface{} {
+	value, ok := req.Params[key]
+	if !ok {
+		return nil
+	}
+
+	if req.matched != nil {
+		for i, k := range req.matched.keys {
+			if k != key || req.matched.matchers[i] == nil {
+				continue
+			}
+			if v, ok := req.matched.matchers[i](value); ok {
+				return v
+			}
+		}
+	}
+	return value
+}
+
+// ParamHandler is called before the handlers of the route whose pattern has the param, value is the param value
+//
+// it is called once per request for one value, call next to continue, or do not call next to end the request
+type ParamHandler func(req *Req, res *Res, next Next, value string)
+
+// paramSet is the param handlers registered to one Route, the routes of the Route share it
+type paramSet struct {
+	handlers map[string][]ParamHandler
+}
+
+type paramCall struct {
+	owner *paramSet
+	name  string
+	index int
+}
+
+// Param add handler of param name, it only apply to the params in the patterns registered in this Route (or Router),
+// including the pattern of Use which mount a Router
+func (r *Route) Param(name string, h ParamHandler) {
+	if h == nil {
+		panic("param handler cannot be nil")
+	}
+
+	name = strings.ToLower(name)
+	if r.params.handlers == nil {
+		r.params.handlers = make(map[string][]ParamHandler)
+	}
+	r.params.handlers[name] = append(r.params.handlers[name], h)
+}
+
+// paramOwners return the paramSet of the route which define each param of the flattened route, host params first
+func paramOwners(mounts []*route, r *route) []*paramSet {
+	var owners []*paramSet
+	for _, v := range mounts {
+		if v.host != nil {
+			for range v.host.keys {
+				owners = append(owners, v.owner)
+			}
+		}
+	}
+	for _, v := range append(append([]*route(nil), mounts...), r) {
+		for _, seg := range parsePattern(v.routePath) {
+			if seg.kind != staticSegment {
+				owners = append(owners, v.owner)
+			}
+		}
+	}
+	return owners
+}
+
+// nextParamHandler return the first param handler of the matched route, which is not called with the param value in this request
+func (req *Req) nextParamHandler(l *leaf) (ParamHandler, string, bool) {
+	for i, key := range l.keys {
+		if i >= len(l.owners) || l.owners[i] == nil {
+			continue
+		}
+
+		value := req.Params[key]
+		for j, h := range l.owners[i].handlers[key] {
+			call := paramCall{owner: l.owners[i], name: key, index: j}
+			if v, ok := req.paramCalled[call]; ok && v == value {
+				continue
+			}
+			if req.paramCalled == nil {
+				req.paramCalled = make(map[paramCall]string)
+			}
+			req.paramCalled[call] = value
+			return h, value, true
+		}
+	}
+	return nil, "", false
+}
@@ -0,0 +1,122 @@
+package gor
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSegment(t *testing.T) {
+	as := assert.New(t)
+
+	seg, err := parseSegment(`:ID(\d+)?`)
+	as.Nil(err)
+	as.Equal(paramSegment, seg.kind)
+	as.Equal("id", seg.value)
+	as.Equal(`\d+`, seg.regexp)
+	as.True(seg.optional)
+	as.NotNil(seg.matcher)
+
+	seg, err = parseSegment(`:id<int>`)
+	as.Nil(err)
+	as.Equal("id", seg.value)
+	as.Equal("<int>", seg.constraint)
+	as.False(seg.optional)
+
+	_, err = parseSegment(`:id<not-exist>`)
+	as.NotNil(err)
+	_, err = parseSegment(`:id(\d+`)
+	as.NotNil(err)
+	_, err = parseSegment(`:id([)`)
+	as.NotNil(err)
+}
+
+func TestParamMatcher(t *testing.T) {
+	app, ts, e, as := newTestServer(t)
+	defer ts.Close()
+
+	RegisterParamMatcher("lower", func(value string) (interface{}, bool) {
+		return value, strings.ToLower(value) == value
+	})
+	as.Panics(func() { RegisterParamMatcher("nil", nil) })
+
+	app.Get(`/users/:id(\d+)`, func(req *Req, res *Res) { res.JSON(req.Params) })
+	app.Get(`/users/me`, func(req *Req, res *Res) { res.Send("me") })
+	app.Get(`/users/:name`, func(req *Req, res *Res) { res.Send("name") })
+	app.Get(`/int/:id<int>`, func(req *Req, res *Res) {
+		res.JSON(map[string]interface{}{"id": req.ParamValue("id"), "not-exist": req.ParamValue("x")})
+	})
+	app.Get(`/uuid/:uuid<uuid>`, func(req *Req, res *Res) { res.Send(req.ParamValue("uuid")) })
+	app.Get(`/alpha/:slug<alpha>/:other`, func(req *Req, res *Res) { res.Send(req.ParamValue("other")) })
+	app.Get(`/lower/:s<lower>`, func(req *Req, res *Res) { res.Send(req.ParamValue("s")) })
+	as.Panics(func() { app.Get(`/not-exist/:s<not-exist>`, func(req *Req, res *Res) {}) })
+
+	e.GET("/users/1").Expect().Status(http.StatusOK).JSON().Equal(map[string]string{"id": "1"})
+	e.GET("/users/me").Expect().Status(http.StatusOK).Text().Equal("me")
+	e.GET("/users/x1").Expect().Status(http.StatusOK).Text().Equal("name")
+	e.GET("/int/12").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"id": 12, "not-exist": nil})
+	e.GET("/int/a").Expect().Status(http.StatusNotFound)
+	e.GET("/uuid/123e4567-e89b-12d3-a456-426655440000").Expect().Status(http.StatusOK).Text().Equal("123e4567-e89b-12d3-a456-426655440000")
+	e.GET("/uuid/123").Expect().Status(http.StatusNotFound)
+	e.GET("/alpha/abc/1").Expect().Status(http.StatusOK).Text().Equal("1")
+	e.GET("/alpha/ab1/1").Expect().Status(http.StatusNotFound)
+	e.GET("/lower/abc").Expect().Status(http.StatusOK).Text().Equal("abc")
+	e.GET("/lower/ABC").Expect().Status(http.StatusNotFound)
+}
+
+func TestParam(t *testing.T) {
+	app, ts, e, as := newTestServer(t)
+	defer ts.Close()
+
+	var calls []string
+	app.Param("ID", func(req *Req, res *Res, next Next, value string) {
+		calls = append(calls, "id:"+value)
+		switch value {
+		case "0":
+			res.Status(http.StatusNotFound).Send("user not found")
+		case "skip":
+			next("route")
+		default:
+			req.AddContext("user", "user"+value)
+			next()
+		}
+	})
+	app.Param("pid", func(req *Req, res *Res, next Next, value string) {
+		calls = append(calls, "app pid:"+value)
+		next()
+	})
+
+	posts := NewRouter()
+	posts.Param("pid", func(req *Req, res *Res, next Next, value string) {
+		calls = append(calls, "pid:"+value)
+		next()
+	})
+	posts.Get("/:pid", func(req *Req, res *Res) {
+		res.Send(req.GetContext("user").(string) + " post" + req.Params["pid"])
+	})
+
+	app.Use("/users/:id", func(req *Req, res *Res, next Next) { next() })
+	app.Get("/users/:id", func(req *Req, res *Res) { res.Send(req.GetContext("user")) })
+	app.Get("/skip/:id", func(req *Req, res *Res) { res.Send("not skipped") })
+	app.Get("/skip/:id", func(req *Req, res *Res) { res.Send("skipped") })
+	app.Use("/users/:id/posts", posts)
+
+	e.GET("/users/1").Expect().Status(http.StatusOK).Text().Equal("user1")
+	as.Equal([]string{"id:1"}, calls)
+
+	calls = nil
+	e.GET("/users/0").Expect().Status(http.StatusNotFound).Text().Equal("user not found")
+	as.Equal([]string{"id:0"}, calls)
+
+	calls = nil
+	e.GET("/skip/skip").Expect().Status(http.StatusOK).Text().Equal("skipped")
+	as.Equal([]string{"id:skip"}, calls)
+
+	calls = nil
+	e.GET("/users/2/posts/3").Expect().Status(http.StatusOK).Text().Equal("user2 post3")
+	as.Equal([]string{"id:2", "pid:3"}, calls)
+
+	as.Panics(func() { app.Param("id", nil) })
+}
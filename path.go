@@ -0,0 +1,132 @@
+package gor
+
+import (
+	"net/http"
+)
+
+// PathRoute is the builder of the routes share one pattern, create by Route.Path
+//
+//	app.Path("/users/:id").Use(auth).Get(show).Put(update).Delete(remove)
+type PathRoute struct {
+	route   *Route
+	pattern string
+	name    string
+
+	// middleware exec before the route middleware and handler of every method
+	middleware []handler
+
+	entries []pathEntry
+}
+
+type pathEntry struct {
+	route *route
+	chain []handler
+}
+
+// Path return the builder to register handlers of methods with the same pattern
+func (r *Route) Path(pattern string) *PathRoute {
+	return &PathRoute{
+		route:   r,
+		pattern: pattern,
+	}
+}
+
+// Use add middleware to every method of the path, no matter registered before or after
+func (p *PathRoute) Use(hs ...interface{}) *PathRoute {
+	p.middleware = append(p.middleware, toHandlers(hs)...)
+	for _, v := range p.entries {
+		v.route.chain = p.chainOf(v.chain)
+	}
+	return p
+}
+
+// Name set the name of all routes of the path, which is used by URLFor
+func (p *PathRoute) Name(name string) *PathRoute {
+	p.name = name
+	for _, v := range p.entries {
+		v.route.name = name
+	}
+	return p
+}
+
+// Methods return the methods registered of the path, in registration order
+func (p *PathRoute) Methods() []string {
+	var methods []string
+	for _, v := range p.entries {
+		exist := false
+		for _, m := range methods {
+			if m == v.route.method {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			methods = append(methods, v.route.method)
+		}
+	}
+	return methods
+}
+
+// Get http get method
+func (p *PathRoute) Get(hs ...interface{}) *PathRoute {
+	return p.handle(http.MethodGet, hs)
+}
+
+// Head http head method
+func (p *PathRoute) Head(hs ...interface{}) *PathRoute {
+	return p.handle(http.MethodHead, hs)
+}
+
+// Post http post method
+func (p *PathRoute) Post(hs ...interface{}) *PathRoute {
+	return p.handle(http.MethodPost, hs)
+}
+
+// Put http put method
+func (p *PathRoute) Put(hs ...interface{}) *PathRoute {
+	return p.handle(http.MethodPut, hs)
+}
+
+// Patch http patch method
+func (p *PathRoute) Patch(hs ...interface{}) *PathRoute {
+	return p.handle(http.MethodPatch, hs)
+}
+
+// Delete http delete method
+func (p *PathRoute) Delete(hs ...interface{}) *PathRoute {
+	return p.handle(http.MethodDelete, hs)
+}
+
+// Connect http connect method
+func (p *PathRoute) Connect(hs ...interface{}) *PathRoute {
+	return p.handle(http.MethodConnect, hs)
+}
+
+// Options http options method
+func (p *PathRoute) Options(hs ...interface{}) *PathRoute {
+	return p.handle(http.MethodOptions, hs)
+}
+
+// Trace http trace method
+func (p *PathRoute) Trace(hs ...interface{}) *PathRoute {
+	return p.handle(http.MethodTrace, hs)
+}
+
+func (p *PathRoute) handle(method string, hs []interface{}) *PathRoute {
+	entry := p.route.addMethodRoute(method, p.pattern, hs)
+	chain := entry.route.chain
+	entry.route.chain = p.chainOf(chain)
+	if p.name != "" {
+		entry.route.name = p.name
+	}
+	p.entries = append(p.entries, pathEntry{route: entry.route, chain: chain})
+	return p
+}
+
+// chainOf return the path middleware followed by the route middleware
+func (p *PathRoute) chainOf(chain []handler) []handler {
+	if len(p.middleware) == 0 {
+		return chain
+	}
+	return append(append([]handler(nil), p.middleware...), chain...)
+}
@@ -0,0 +1,48 @@
+package gor
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestPathRoute(t *testing.T) {
+	app, ts, e, as := newTestServer(t)
+	defer ts.Close()
+
+	mid := func(req *Req, res *Res, next Next) {
+		res.AddHeader("X-Path", "1")
+		next()
+	}
+	user := app.Path("/users/:id").
+		Get(func(req *Req, res *Res) { res.Send("get " + req.Params["id"]) }).
+		Use(mid).
+		Put(func(req *Req, res *Res, next Next) {
+			res.AddHeader("X-Route", "1")
+			next()
+		}, func(req *Req, res *Res) { res.Send("put " + req.Params["id"]) }).
+		Delete(func(req *Req, res *Res) { res.Send("delete " + req.Params["id"]) }).
+		Name("user")
+
+	as.Equal([]string{http.MethodGet, http.MethodPut, http.MethodDelete}, user.Methods())
+
+	r := e.GET("/users/1").Expect().Status(http.StatusOK)
+	r.Text().Equal("get 1")
+	r.Header("X-Path").Equal("1")
+	r = e.PUT("/users/2").Expect().Status(http.StatusOK)
+	r.Text().Equal("put 2")
+	r.Header("X-Path").Equal("1")
+	r.Header("X-Route").Equal("1")
+	e.DELETE("/users/3").Expect().Status(http.StatusOK).Text().Equal("delete 3")
+	e.POST("/users/3").Expect().Status(http.StatusMethodNotAllowed).Header("Allow").Equal("GET, PUT, DELETE, HEAD, OPTIONS")
+
+	u, err := app.URLFor("user", map[string]string{"id": "4"}, nil)
+	as.Nil(err)
+	as.Equal("/users/4", u)
+
+	routes := app.Routes()
+	as.Len(routes, 3)
+	as.Len(routes[1].Middleware, 2)
+
+	as.Panics(func() { app.Path("/a").Get() })
+	as.Panics(func() { app.Path("/a").Use() })
+}
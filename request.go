@@ -3,25 +3,54 @@ package gor
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
 	"strings"
 )
 
-type bodyData struct {
-	JSON           interface{}
+// BodyData is the parsed request body, only one of the fields is set by the Content-Type of request
+type BodyData struct {
+	// JSON is set when Content-Type is application/json, or application/*+json
+	JSON interface{}
+	// FormURLEncoded is set when Content-Type is application/x-www-form-urlencoded
 	FormURLEncoded map[string][]string
-	FormData       map[string][]string
+	// FormData and Files are set when Content-Type is multipart/form-data
+	FormData map[string][]string
+	Files    map[string][]*File
 }
 
+// defaultMultipartMemory is the max bytes of multipart form stored in memory, the remaining files are stored in temporary files
+const defaultMultipartMemory = 32 << 20
+
 // Req is http Request struct
 // <scheme>://<username>:<password>@<host>:<port>/<path>;<parameters>?<query>#<fragment>
 type Req struct {
 	r       *http.Request
 	context context.Context
+	matched *leaf
+	allowed []string
+	// param handlers called, and the param value
+	paramCalled map[paramCall]string
+	// params of the parent application, when in sub application
+	mountParams map[string]string
+	// err is passed by next(err), and handled by ErrorHandlerFunc
+	err error
+	// correlationID is set when CorrelationID is called
+	correlationID string
+	// body and bodyErr are set when Body is called
+	body    *BodyData
+	bodyErr error
+	// bodyLimit is the limits of the matched route, the body is limited when it is read first time
+	bodyLimit   BodyLimit
+	bodyLimited bool
+	// tempFiles is the temporary files created by MultipartReader, they are removed when the request is finished
+	tempFiles []string
 
 	Protocol string
 	Secure   bool
@@ -32,9 +61,12 @@ type Req struct {
 
 	BaseURL     string
 	OriginalURL string
+	// MountPath is the path matched by the pattern which the sub application (Gor) is mounted at, empty if not in sub application
+	MountPath string
+	// Path is the path relative to MountPath, it is used to route the request
+	Path string
 
 	Params map[string]string
-	Body   *bodyData
 }
 
 func getProtocol(r *http.Request) string {
@@ -80,27 +112,59 @@ func getOriginalURL(r *http.Request) string {
 	return r.URL.Path
 }
 
-func getBody(r *http.Request) (*bodyData, error) {
-	var t interface{}
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		return nil, err
+// parseBody parse the body by the Content-Type with the limits, the body of other Content-Type is not read
+func (req *Req) parseBody() (*BodyData, error) {
+	r, limit := req.r, req.bodyLimit
+	if r.Body == nil || r.Body == http.NoBody {
+		return &BodyData{}, nil
 	}
-	r.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-
-	if err = json.Unmarshal(body, &t); err == nil {
-		return &bodyData{JSON: t}, nil
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return &BodyData{}, nil
 	}
 
-	if err := r.ParseForm(); err == nil && r.PostForm.Encode() != "" {
-		return &bodyData{FormURLEncoded: r.PostForm}, nil
-	}
+	switch {
+	case contentType == "application/json" || (strings.HasPrefix(contentType, "application/") && strings.HasSuffix(contentType, "+json")):
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return &BodyData{}, err
+		}
+		// the body can be read again, like BindJSON
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
 
-	if err := r.ParseMultipartForm(2 ^ 10); err == nil {
-		return &bodyData{FormData: r.PostForm}, nil
+		var t interface{}
+		if err := json.Unmarshal(body, &t); err != nil {
+			return &BodyData{}, err
+		}
+		return &BodyData{JSON: t}, nil
+	case contentType == "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return &BodyData{}, err
+		}
+		if err := limit.checkForm(r); err != nil {
+			return &BodyData{}, err
+		}
+		return &BodyData{FormURLEncoded: r.PostForm}, nil
+	case contentType == "multipart/form-data":
+		// the parts are read by MultipartReader, so the limits are checked while the body is read
+		m, err := req.MultipartReader()
+		if err != nil {
+			return &BodyData{}, err
+		}
+		files := make(map[string][]*File)
+		for {
+			f, err := m.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return &BodyData{}, err
+			}
+			files[f.Field] = append(files[f.Field], f)
+		}
+		return &BodyData{FormData: m.Value, Files: files}, nil
 	}
-
-	return nil, nil
+	return &BodyData{}, nil
 }
 
 func httpRequestToReq(r *http.Request) (*Req, error) {
@@ -109,12 +173,8 @@ func httpRequestToReq(r *http.Request) (*Req, error) {
 		return nil, err
 	}
 
-	body, err := getBody(r)
-	if err != nil {
-		return nil, err
-	}
-
 	protocol := getProtocol(r)
+	baseURL := getBaseURL(r)
 
 	return &Req{
 		r:       r,
@@ -127,11 +187,11 @@ func httpRequestToReq(r *http.Request) (*Req, error) {
 		Headers:  r.Header,
 		Hostname: getHostname(r),
 
-		BaseURL:     getBaseURL(r),
+		BaseURL:     baseURL,
 		OriginalURL: getOriginalURL(r),
+		Path:        baseURL,
 
 		Params: make(map[string]string),
-		Body:   body,
 	}, nil
 }
 
@@ -145,8 +205,58 @@ func (req *Req) GetContext(key interface{}) interface{} {
 	return req.context.Value(key)
 }
 
+// CorrelationID return the X-Request-ID (or X-Correlation-ID) header of request,
+// or a random ID if the header is not set, the ID is same in one request
+func (req *Req) CorrelationID() string {
+	if req.correlationID != "" {
+		return req.correlationID
+	}
+
+	h := http.Header(req.Headers)
+	if id := h.Get("X-Request-ID"); id != "" {
+		req.correlationID = id
+	} else if id := h.Get("X-Correlation-ID"); id != "" {
+		req.correlationID = id
+	} else {
+		b := make([]byte, 16)
+		rand.Read(b)
+		req.correlationID = hex.EncodeToString(b)
+	}
+	return req.correlationID
+}
+
+// AllowedMethods return methods registered for the request path,
+// it is only set in method not allowed handler and options handler
+func (req *Req) AllowedMethods() []string {
+	return req.allowed
+}
+
+// Body return the body parsed by the Content-Type of request, it is parsed when Body is called first time
+//
+// the body is not read before Body is called, use BodyReader to read the raw body
+func (req *Req) Body() (*BodyData, error) {
+	if req.body == nil {
+		if err := req.limitBody(); err != nil {
+			req.body, req.bodyErr = &BodyData{}, err
+		} else {
+			req.body, req.bodyErr = req.parseBody()
+			req.bodyErr = bodyError(req.bodyErr)
+		}
+	}
+	return req.body, req.bodyErr
+}
+
+// BodyReader return the raw body of request, it is limited by BodyLimit.MaxBytes
+func (req *Req) BodyReader() io.ReadCloser {
+	req.limitBody()
+	return req.r.Body
+}
+
 // BindJSON body to json
 func (req *Req) BindJSON(v interface{}) error {
+	if err := req.limitBody(); err != nil {
+		return err
+	}
 	defer io.Copy(ioutil.Discard, req.r.Body)
-	return json.NewDecoder(req.r.Body).Decode(v)
+	return bodyError(json.NewDecoder(req.r.Body).Decode(v))
 }
@@ -1,6 +1,7 @@
 package gor
 
 import (
+	"io/ioutil"
 	"net/http"
 	"testing"
 )
@@ -77,13 +78,46 @@ func TestBody(t *testing.T) {
 	defer ts.Close()
 
 	app.Post("/", func(req *Req, res *Res) {
-		res.JSON(map[string]interface{}{"json": req.Body.JSON, "form-data": req.Body.FormData, "form-url-encoded": req.Body.FormURLEncoded})
+		body, _ := req.Body()
+		res.JSON(map[string]interface{}{"json": body.JSON, "form-data": body.FormData, "form-url-encoded": body.FormURLEncoded})
 	})
 	e.POST("/").WithJSON(map[string]string{"s": "d"}).Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"form-data": nil, "form-url-encoded": nil, "json": map[string]string{"s": "d"}})
 	e.POST("/").WithForm(map[string]string{"s": "d"}).Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"form-data": nil, "form-url-encoded": map[string][]string{"s": {"d"}}, "json": nil})
 	e.POST("/").WithMultipart().WithForm(map[string]string{"s": "d"}).Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"form-data": map[string][]string{"s": {"d"}}, "form-url-encoded": nil, "json": nil})
 }
 
+func TestBody_lazy(t *testing.T) {
+	app, ts, e, as := newTestServer(t)
+	defer ts.Close()
+
+	app.Post("/raw", func(req *Req, res *Res) {
+		b, err := ioutil.ReadAll(req.BodyReader())
+		as.Nil(err)
+		res.Send(string(b))
+	})
+	app.Post("/body", func(req *Req, res *Res) error {
+		body, err := req.Body()
+		if err != nil {
+			return BadRequestError("invalid body").WithCause(err)
+		}
+		var v map[string]interface{}
+		if body.JSON != nil {
+			as.Nil(req.BindJSON(&v))
+		}
+		res.JSON(map[string]interface{}{"json": body.JSON, "bind": v, "form-url-encoded": body.FormURLEncoded})
+		return nil
+	})
+
+	e.POST("/raw").WithHeader("Content-Type", "application/json").WithText(`{"s":`).Expect().Status(http.StatusOK).Text().Equal(`{"s":`)
+	e.POST("/body").WithHeader("Content-Type", "application/json").WithText(`{"s":`).Expect().Status(http.StatusBadRequest).Text().Equal("invalid body")
+	e.POST("/body").WithHeader("Content-Type", "application/vnd.api+json").WithText(`{"s":"d"}`).Expect().Status(http.StatusOK).
+		JSON().Equal(map[string]interface{}{"json": map[string]string{"s": "d"}, "bind": map[string]string{"s": "d"}, "form-url-encoded": nil})
+	e.POST("/body").WithHeader("Content-Type", "text/plain").WithText(`{"s":"d"}`).Expect().Status(http.StatusOK).
+		JSON().Equal(map[string]interface{}{"json": nil, "bind": nil, "form-url-encoded": nil})
+	e.POST("/body").WithForm(map[string]string{"s": "d"}).Expect().Status(http.StatusOK).
+		JSON().Equal(map[string]interface{}{"json": nil, "bind": nil, "form-url-encoded": map[string][]string{"s": {"d"}}})
+}
+
 func TestBind(t *testing.T) {
 	app, ts, e, _ := newTestServer(t)
 	defer ts.Close()
@@ -101,3 +135,19 @@ func TestBind(t *testing.T) {
 	})
 	e.POST("/").WithJSON(map[string]interface{}{"name": "1", "age": 24}).Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"name": "1", "age": 24})
 }
+
+func TestParams_catchAll_optional(t *testing.T) {
+	app, ts, e, _ := newTestServer(t)
+	defer ts.Close()
+
+	app.Get("/files/readme", func(req *Req, res *Res) { res.Send("readme") })
+	app.Get("/files/*filepath", func(req *Req, res *Res) { res.JSON(req.Params) })
+	app.Get("/posts/:id?", func(req *Req, res *Res) { res.JSON(req.Params) })
+
+	e.GET("/files").Expect().Status(http.StatusNotFound)
+	e.GET("/files/readme").Expect().Status(http.StatusOK).Text().Equal("readme")
+	e.GET("/files/a/b.txt").Expect().Status(http.StatusOK).JSON().Equal(map[string]string{"filepath": "a/b.txt"})
+	e.GET("/posts").Expect().Status(http.StatusOK).JSON().Equal(map[string]string{})
+	e.GET("/posts/1").Expect().Status(http.StatusOK).JSON().Equal(map[string]string{"id": "1"})
+	e.GET("/posts/1/2").Expect().Status(http.StatusNotFound)
+}
@@ -3,8 +3,10 @@ package gor
 import (
 	"encoding/json"
 	"fmt"
+	"html/template"
 	"net/http"
 	"reflect"
+	"strconv"
 
 	"github.com/unrolled/render"
 )
@@ -14,6 +16,9 @@ type Res struct {
 	w      http.ResponseWriter
 	exit   bool
 	render *render.Render
+	// app is the Gor which is serving the request, and req is the request, they are used to render error
+	app *Gor
+	req *Req
 
 	Response   interface{}
 	StatusCode int
@@ -23,12 +28,18 @@ func httpResponseWriterToRes(httpResponseWriter http.ResponseWriter, g *Gor) *Re
 	return &Res{
 		httpResponseWriter,
 		false,
-		render.New(render.Options{Directory: g.renderDir}),
+		g.newRender(),
+		g,
+		nil,
 		nil,
 		200,
 	}
 }
 
+func (g *Gor) newRender() *render.Render {
+	return render.New(render.Options{Directory: g.renderDir, Funcs: []template.FuncMap{g.templateFuncs()}})
+}
+
 func (res *Res) Write(data []byte) (int, error) {
 	res.exit = true
 	res.Response = string(data)
@@ -137,12 +148,49 @@ func (res *Res) SetCookie(key, val string, option ...Cookie) {
 	http.SetCookie(res.w, cookie)
 }
 
-// Error send erroe Response
-func (res *Res) Error(v string) {
-	res.Status(http.StatusInternalServerError).Send(v)
+// Error send error Response, v is error or message of 500 error
+//
+// the status code is from the StatusCode method of error (500 if error has no StatusCode method),
+// the response is JSON or HTML if the request Accept it, the Err (internal cause) of HTTPError is never responded
+func (res *Res) Error(v interface{}) {
+	res.renderError(errorf(v))
 }
 
 // End end the request
 func (res *Res) End() {
 	res.exit = true
 }
+
+// headResponseWriter discard the body of HEAD request served by GET handler,
+// but keep the headers and Content-Length of the body
+type headResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	length      int
+	wroteHeader bool
+}
+
+func (w *headResponseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *headResponseWriter) Write(data []byte) (int, error) {
+	w.length += len(data)
+	return len(data), nil
+}
+
+func (w *headResponseWriter) finish() {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if w.length > 0 && w.Header().Get("Content-Length") == "" {
+		w.Header().Set("Content-Length", strconv.Itoa(w.length))
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
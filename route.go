@@ -5,20 +5,130 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
-	"regexp"
 	"strings"
+	"sync"
 )
 
 // HandlerFunc gor handler func like http.HandlerFunc func(ResponseWriter, *Request)
 type HandlerFunc func(*Req, *Res)
 
 // Next exec next handler or mid
-type Next func(...string)
+//
+//	next()         exec next handler
+//	next("route")  skip the remaining handlers of current route
+//	next("router") skip the remaining handlers of current Router, and continue in the parent
+//	next(err)      stop and send the error
+type Next func(...interface{})
 
 // HandlerFuncNext gor handler func like http.HandlerFunc func(ResponseWriter, *Request),
 // but return HandlerFunc to do somrthing at defer time
 type HandlerFuncNext func(*Req, *Res, Next)
 
+// HandlerFuncWithError is HandlerFunc which return error, the error is passed to next(err)
+type HandlerFuncWithError func(*Req, *Res) error
+
+// HandlerFuncNextWithError is HandlerFuncNext which return error, the error is passed to next(err)
+type HandlerFuncNextWithError func(*Req, *Res, Next) error
+
+// ErrorHandlerFunc handle the error passed by next(err), it is skipped when there is no error
+//
+// call next(err) to pass the error to the next ErrorHandlerFunc, or next() to continue the normal handlers
+type ErrorHandlerFunc func(error, *Req, *Res, Next)
+
+// handler is one of HandlerFunc, HandlerFuncNext and ErrorHandlerFunc
+type handler struct {
+	handlerFunc     HandlerFunc
+	handlerFuncNext HandlerFuncNext
+	errorHandler    ErrorHandlerFunc
+
+	// label is the name of the original handler, when it is converted from net/http
+	label string
+}
+
+// toHandler convert h to handler, h can be gor.HandlerFunc / gor.HandlerFuncNext,
+// or net/http http.Handler / func(http.ResponseWriter, *http.Request) / func(http.Handler) http.Handler
+func toHandler(h interface{}) (handler, error) {
+	switch f := h.(type) {
+	case HandlerFunc:
+		return handler{handlerFunc: f}, nil
+	case HandlerFuncNext:
+		return handler{handlerFuncNext: f}, nil
+	case func(req *Req, res *Res):
+		return handler{handlerFunc: f}, nil
+	case func(req *Req, res *Res, next Next):
+		return handler{handlerFuncNext: f}, nil
+	case HandlerFuncWithError:
+		return handler{handlerFuncNext: withError(f), label: funcName(f)}, nil
+	case func(req *Req, res *Res) error:
+		return handler{handlerFuncNext: withError(f), label: funcName(f)}, nil
+	case HandlerFuncNextWithError:
+		return handler{handlerFuncNext: nextWithError(f), label: funcName(f)}, nil
+	case func(req *Req, res *Res, next Next) error:
+		return handler{handlerFuncNext: nextWithError(f), label: funcName(f)}, nil
+	case ErrorHandlerFunc:
+		return handler{errorHandler: f}, nil
+	case func(err error, req *Req, res *Res, next Next):
+		return handler{errorHandler: f}, nil
+	case func(w http.ResponseWriter, r *http.Request):
+		return handler{handlerFunc: httpHandler(http.HandlerFunc(f)), label: funcName(f)}, nil
+	case func(http.Handler) http.Handler:
+		return handler{handlerFuncNext: httpMiddleware(f), label: funcName(f)}, nil
+	case http.Handler:
+		return handler{handlerFunc: httpHandler(f), label: httpHandlerName(f)}, nil
+	}
+	return handler{}, fmt.Errorf("maybe you are transmiting gor.HandlerFunc / gor.HandlerFuncNext / http.Handler, but the function signature is wrong")
+}
+
+// toHandlers convert every one of hs to handler, panic if hs is empty or any one is wrong
+func toHandlers(hs []interface{}) []handler {
+	if len(hs) == 0 {
+		panic("handler cannot be empty")
+	}
+
+	var handlers []handler
+	for _, v := range hs {
+		h, err := toHandler(v)
+		if err != nil {
+			panic(err)
+		}
+		handlers = append(handlers, h)
+	}
+	return handlers
+}
+
+// withError convert HandlerFuncWithError to HandlerFuncNext, which continue like HandlerFunc if there is no error
+func withError(f HandlerFuncWithError) HandlerFuncNext {
+	return func(req *Req, res *Res, next Next) {
+		if err := f(req, res); err != nil {
+			next(err)
+		} else {
+			next()
+		}
+	}
+}
+
+// nextWithError convert HandlerFuncNextWithError to HandlerFuncNext
+func nextWithError(f HandlerFuncNextWithError) HandlerFuncNext {
+	return func(req *Req, res *Res, next Next) {
+		if err := f(req, res, next); err != nil {
+			next(err)
+		}
+	}
+}
+
+func (h handler) name() string {
+	if h.label != "" {
+		return h.label
+	} else if h.handlerFunc != nil {
+		return funcName(h.handlerFunc)
+	} else if h.handlerFuncNext != nil {
+		return funcName(h.handlerFuncNext)
+	} else if h.errorHandler != nil {
+		return funcName(h.errorHandler)
+	}
+	return ""
+}
+
 type matchType int
 
 const (
@@ -31,28 +141,62 @@ type route struct {
 	method    string
 	routePath string
 	matchType matchType
-
-	routePathReg *regexp.Regexp
+	name      string
+	// host is set when the route is mounted by Host
+	host *hostPattern
+	// owner is the param handlers of the Route which the route is registered to
+	owner *paramSet
+	// trailingSlash report whether the pattern has trailing slash, it is used when strict slash
+	trailingSlash bool
+	// app is the sub application mounted by the route
+	app *Gor
+	// bodyLimit is set by RouteEntry.BodyLimit
+	bodyLimit *BodyLimit
+	// notFound is the not found handler of the mounted Route (Router), it is served after the children
+	notFound HandlerFunc
 
 	handlerFunc     HandlerFunc
 	handlerFuncNext HandlerFuncNext
+	errorHandler    ErrorHandlerFunc
+	handlerLabel    string
 	middleware      Middleware
 
+	// chain is the route middleware which exec before handlerFunc / handlerFuncNext / errorHandler
+	chain []handler
+
 	children []*route
 }
 
+// step return the i-th handler of route, the chain and then the handler
+func (r *route) step(i int) handler {
+	if i < len(r.chain) {
+		return r.chain[i]
+	}
+	return handler{handlerFunc: r.handlerFunc, handlerFuncNext: r.handlerFuncNext, errorHandler: r.errorHandler, label: r.handlerLabel}
+}
+
 func (r *route) copy() *route {
 	return &route{
 		method:    r.method,
 		routePath: r.routePath,
 		matchType: r.matchType,
+		name:      r.name,
+		host:      r.host,
+		owner:     r.owner,
 
-		routePathReg: r.routePathReg,
+		trailingSlash: r.trailingSlash,
+		app:           r.app,
+		bodyLimit:     r.bodyLimit,
+		notFound:      r.notFound,
 
 		handlerFunc:     r.handlerFunc,
 		handlerFuncNext: r.handlerFuncNext,
+		errorHandler:    r.errorHandler,
+		handlerLabel:    r.handlerLabel,
 		middleware:      r.middleware,
 
+		chain: r.chain,
+
 		children: copyRouteSlice(r.children),
 	}
 }
@@ -68,60 +212,109 @@ func copyRouteSlice(routes []*route) []*route {
 // Route route
 type Route struct {
 	routes []*route
+	tree   *tree
+	params *paramSet
+
+	// notFoundHandler serve the request which is not handled by the routes
+	notFoundHandler HandlerFunc
+
+	// app serve the request when Route is used as http.Handler
+	app     *Gor
+	appOnce sync.Once
 }
 
 // NewRoute return *Router
 func NewRoute() *Route {
-	return &Route{}
+	return &Route{
+		tree:   newTree(),
+		params: &paramSet{},
+	}
+}
+
+func (r *Route) addRoute(route *route) *RouteEntry {
+	if route.owner == nil {
+		route.owner = r.params
+	}
+	r.tree.insert("", nil, route)
+	r.routes = append(r.routes, route)
+	return &RouteEntry{route: route}
+}
+
+// RouteEntry is a registered route, use it to config the route
+type RouteEntry struct {
+	route *route
+}
+
+// Name set the name of route, which is used by URLFor
+//
+// name of Group is the prefix of names of routes in the group
+func (e *RouteEntry) Name(name string) *RouteEntry {
+	e.route.name = name
+	return e
 }
 
 func (r *Route) handler(pattern string) []*route {
 	return copyRouteSlice(r.routes)
 }
 
+func (r *Route) root() *Route {
+	return r
+}
+
+// SetNotFoundHandler set the handler to response the request which is not handled by the routes,
+// or failed with ErrNotFound, NotFoundError() or the error with 404 StatusCode
+//
+// the handler of Gor serve the request which is not handled by any route, after the error handler middleware,
+// the handler of Router or sub application serve the request under its mount path, the handler of Router must be set before it is mounted
+//
+// set nil to pass the request to the parent, the default handler of Gor response 404 by Res.Error
+func (r *Route) SetNotFoundHandler(h HandlerFunc) {
+	r.notFoundHandler = h
+}
+
 // Get http get method
-func (r *Route) Get(pattern string, h HandlerFunc) {
-	r.addHandlerFuncAndNextRoute(http.MethodGet, pattern, fullMatch, h, nil)
+func (r *Route) Get(pattern string, hs ...interface{}) *RouteEntry {
+	return r.addMethodRoute(http.MethodGet, pattern, hs)
 }
 
 // Head http head method
-func (r *Route) Head(pattern string, h HandlerFunc) {
-	r.addHandlerFuncAndNextRoute(http.MethodHead, pattern, fullMatch, h, nil)
+func (r *Route) Head(pattern string, hs ...interface{}) *RouteEntry {
+	return r.addMethodRoute(http.MethodHead, pattern, hs)
 }
 
 // Post http post method
-func (r *Route) Post(pattern string, h HandlerFunc) {
-	r.addHandlerFuncAndNextRoute(http.MethodPost, pattern, fullMatch, h, nil)
+func (r *Route) Post(pattern string, hs ...interface{}) *RouteEntry {
+	return r.addMethodRoute(http.MethodPost, pattern, hs)
 }
 
 // Put http put method
-func (r *Route) Put(pattern string, h HandlerFunc) {
-	r.addHandlerFuncAndNextRoute(http.MethodPut, pattern, fullMatch, h, nil)
+func (r *Route) Put(pattern string, hs ...interface{}) *RouteEntry {
+	return r.addMethodRoute(http.MethodPut, pattern, hs)
 }
 
 // Patch http patch method
-func (r *Route) Patch(pattern string, h HandlerFunc) {
-	r.addHandlerFuncAndNextRoute(http.MethodPatch, pattern, fullMatch, h, nil)
+func (r *Route) Patch(pattern string, hs ...interface{}) *RouteEntry {
+	return r.addMethodRoute(http.MethodPatch, pattern, hs)
 }
 
 // Delete http delete method
-func (r *Route) Delete(pattern string, h HandlerFunc) {
-	r.addHandlerFuncAndNextRoute(http.MethodDelete, pattern, fullMatch, h, nil)
+func (r *Route) Delete(pattern string, hs ...interface{}) *RouteEntry {
+	return r.addMethodRoute(http.MethodDelete, pattern, hs)
 }
 
 // Connect http connect method
-func (r *Route) Connect(pattern string, h HandlerFunc) {
-	r.addHandlerFuncAndNextRoute(http.MethodConnect, pattern, fullMatch, h, nil)
+func (r *Route) Connect(pattern string, hs ...interface{}) *RouteEntry {
+	return r.addMethodRoute(http.MethodConnect, pattern, hs)
 }
 
 // Options http options method
-func (r *Route) Options(pattern string, h HandlerFunc) {
-	r.addHandlerFuncAndNextRoute(http.MethodOptions, pattern, fullMatch, h, nil)
+func (r *Route) Options(pattern string, hs ...interface{}) *RouteEntry {
+	return r.addMethodRoute(http.MethodOptions, pattern, hs)
 }
 
 // Trace http trace method
-func (r *Route) Trace(pattern string, h HandlerFunc) {
-	r.addHandlerFuncAndNextRoute(http.MethodTrace, pattern, fullMatch, h, nil)
+func (r *Route) Trace(pattern string, hs ...interface{}) *RouteEntry {
+	return r.addMethodRoute(http.MethodTrace, pattern, hs)
 }
 
 // Use http trace method
@@ -130,7 +323,13 @@ func (r *Route) Trace(pattern string, h HandlerFunc) {
 // string
 // type HandlerFunc func(*Req, *Res)
 // type HandlerFuncNext func(*Req, *Res, Next)
+// type ErrorHandlerFunc func(error, *Req, *Res, Next)
+// type HandlerFuncWithError func(*Req, *Res) error
+// type HandlerFuncNextWithError func(*Req, *Res, Next) error
 // type Middleware interface
+// *Gor, it is mounted as sub application with its own settings
+// http.Handler, func(http.ResponseWriter, *http.Request), the prefix of path is stripped
+// func(http.Handler) http.Handler
 func (r *Route) Use(hs ...interface{}) {
 	r.use(preMatch, hs...)
 }
@@ -141,10 +340,10 @@ func (r *Route) All(hs ...interface{}) {
 }
 
 // Group like all but donnot new a router
-func (r *Route) Group(pattern string, app func(group *Router)) {
+func (r *Route) Group(pattern string, app func(group *Router)) *RouteEntry {
 	router := NewRouter()
 	app(router)
-	r.All(pattern, router)
+	return r.useWithMiddleware("ALL", pattern, onlyLastFull, router)
 }
 
 func (r *Route) use(matchType matchType, hs ...interface{}) {
@@ -168,6 +367,14 @@ func (r *Route) use(matchType matchType, hs ...interface{}) {
 	}
 }
 
+// addMethodRoute add route with route middleware, the last one of hs is the handler
+//
+// every one of hs must belong gor.HandlerFunc / gor.HandlerFuncNext / gor.ErrorHandlerFunc / http.Handler
+func (r *Route) addMethodRoute(method, pattern string, hs []interface{}) *RouteEntry {
+	chain := toHandlers(hs)
+	return r.addHandlerRoute(method, pattern, fullMatch, chain[len(chain)-1], chain[:len(chain)-1]...)
+}
+
 func (r *Route) useWithOne(pattern string, matchType matchType, h interface{}) {
 	var err error
 	defer func() {
@@ -175,72 +382,73 @@ func (r *Route) useWithOne(pattern string, matchType matchType, h interface{}) {
 			panic(err)
 		}
 	}()
+	if app, ok := h.(*Gor); ok {
+		r.mount(pattern, app)
+		return
+	}
+	if f, ok := h.(Middleware); ok {
+		r.useWithMiddleware("ALL", pattern, matchType, f)
+		return
+	}
+
 	hType := reflect.TypeOf(h)
-	switch hType.Kind() {
-	case reflect.Func:
-		switch h.(type) {
-		case func(req *Req, res *Res):
-			if f, ok := h.(func(req *Req, res *Res)); ok {
-				r.addHandlerFuncAndNextRoute("ALL", pattern, matchType, HandlerFunc(f), nil)
-			} else {
-				err = fmt.Errorf("cannot convert to gor.HandlerFunc")
-			}
-		case func(req *Req, res *Res, next Next):
-			if f, ok := h.(func(req *Req, res *Res, next Next)); ok {
-				r.addHandlerFuncAndNextRoute("ALL", pattern, matchType, nil, HandlerFuncNext(f))
-			} else {
-				err = fmt.Errorf("cannot convert to gor.HandlerFuncNext")
-			}
-		default:
-			err = fmt.Errorf("maybe you are transmiting gor.HandlerFunc / gor.HandlerFuncNext, but the function signature is wrong")
+	if _, ok := h.(http.Handler); ok || hType.Kind() == reflect.Func {
+		var hd handler
+		if hd, err = toHandler(h); err == nil {
+			r.addHandlerRoute("ALL", pattern, matchType, hd)
 		}
+		return
+	}
+
+	switch hType.Kind() {
 	case reflect.Struct:
 		err = fmt.Errorf("maybe you are transmiting gor.Middleware, but please use Pointer, not Struct")
 	case reflect.Ptr:
-		if f, ok := h.(Middleware); ok {
-			r.useWithMiddleware("ALL", pattern, matchType, f)
-		} else {
-			err = fmt.Errorf("cannot convert to gor.Middleware")
-		}
+		err = fmt.Errorf("cannot convert to gor.Middleware or http.Handler")
 	default:
-		err = fmt.Errorf("when middleware length is one, that type must belong gor.HandlerFunc / gor.HandlerFuncNext / gor.Route, but get %s", hType.Kind())
+		err = fmt.Errorf("when middleware length is one, that type must belong gor.HandlerFunc / gor.HandlerFuncNext / gor.Route / http.Handler, but get %s", hType.Kind())
 	}
 }
 
-func (r *Route) addHandlerFuncAndNextRoute(method string, pattern string, matchType matchType, h HandlerFunc, hn HandlerFuncNext) {
+func (r *Route) addHandlerRoute(method string, pattern string, matchType matchType, h handler, chain ...handler) *RouteEntry {
+	return r.addRoute(newHandlerRoute(method, pattern, matchType, h, chain...))
+}
+
+func newHandlerRoute(method string, pattern string, matchType matchType, h handler, chain ...handler) *route {
 	if !strings.HasPrefix(pattern, "/") {
 		panic("must start with /")
 	}
-	if strings.HasSuffix(pattern, "/") && pattern != "/" {
+	trailingSlash := strings.HasSuffix(pattern, "/") && pattern != "/"
+	if trailingSlash {
 		pattern = pattern[:len(pattern)-1]
 	}
 
-	URL, err := url.Parse(pattern)
+	// donot use url.Parse, `?` is the optional param syntax of pattern
+	routePath, err := url.PathUnescape(pattern)
 	if err != nil {
 		panic(fmt.Sprintf("pattern invalid: %s", pattern))
 	}
 
-	routePath := URL.Path
-
 	var routeH = &route{
 		method:    method,
 		routePath: routePath,
 		matchType: matchType,
 
-		routePathReg: genMatchPathReg(routePath),
+		chain: chain,
+
+		trailingSlash: trailingSlash,
 	}
-	if h != nil {
-		routeH.handlerFunc = h
-	} else if hn != nil {
-		routeH.handlerFuncNext = hn
-	} else {
-		panic("handlerFunc or handlerFuncNext cannot be both nil")
+	if h.handlerFunc == nil && h.handlerFuncNext == nil && h.errorHandler == nil {
+		panic("handlerFunc, handlerFuncNext and errorHandler cannot be all nil")
 	}
-
-	r.routes = append(r.routes, routeH)
+	routeH.handlerFunc = h.handlerFunc
+	routeH.handlerFuncNext = h.handlerFuncNext
+	routeH.errorHandler = h.errorHandler
+	routeH.handlerLabel = h.label
+	return routeH
 }
 
-func (r *Route) useWithMiddleware(method, pattern string, matchType matchType, mid Middleware) {
+func (r *Route) useWithMiddleware(method, pattern string, matchType matchType, mid Middleware) *RouteEntry {
 	if method != "ALL" {
 		panic("middleware method must be ALL")
 	}
@@ -249,21 +457,15 @@ func (r *Route) useWithMiddleware(method, pattern string, matchType matchType, m
 		fixMatchType(subRoutes)
 	}
 
-	// 重新计算subRoutes的正则
-	for _, v := range subRoutes {
-		v.routePathReg = genMatchPathReg(pattern + v.routePath)
-	}
-
 	parent := &route{
 		method:    method,
 		matchType: matchType,
 		routePath: pattern,
 
-		routePathReg: genMatchPathReg(pattern),
-
+		notFound: mid.root().notFoundHandler,
 		children: subRoutes,
 	}
-	r.routes = append(r.routes, parent)
+	return r.addRoute(parent)
 }
 
 func fixMatchType(roures []*route) {
@@ -1,6 +1,8 @@
 package gor
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -46,6 +48,21 @@ func assertBetweenRoute(as *assert.Assertions, expected, actual *route) {
 	}
 }
 
+// lookupRoutes return the routes matched by (method, requestPath), with the full path of the leaf
+func lookupRoutes(t *tree, method, requestPath string) []*route {
+	m := acquireMatcher()
+	defer releaseMatcher(m)
+	t.lookup(m, method, requestPath)
+
+	var routes []*route
+	for _, v := range m.matches {
+		r := v.leaf.route.copy()
+		r.routePath = v.leaf.path
+		routes = append(routes, r)
+	}
+	return routes
+}
+
 func assertOneRoute(as *assert.Assertions, method, routePath string, matchType matchType, handlerType handlerType, actuals []*route) {
 	as.Len(actuals, 1)
 	assertRoute(as, method, routePath, matchType, handlerType, actuals[0])
@@ -122,9 +139,9 @@ func TestRoute_fullmatch(t *testing.T) {
 	assertRoute(as, "GET", "/2", fullMatch, handlerFunc, app.routes[1])
 	assertRoute(as, "GET", "/3/3", fullMatch, handlerFunc, app.routes[2])
 
-	assertBetweenRoute(as, app.routes[0], matchRouter("GET", "/", app.routes)[0])
-	assertBetweenRoute(as, app.routes[1], matchRouter("GET", "/2", app.routes)[0])
-	assertBetweenRoute(as, app.routes[2], matchRouter("GET", "/3/3", app.routes)[0])
+	assertBetweenRoute(as, app.routes[0], lookupRoutes(app.tree, "GET", "/")[0])
+	assertBetweenRoute(as, app.routes[1], lookupRoutes(app.tree, "GET", "/2")[0])
+	assertBetweenRoute(as, app.routes[2], lookupRoutes(app.tree, "GET", "/3/3")[0])
 
 	e.GET("/?a=b").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"params": map[string]string{}, "query": map[string][]string{"a": {"b"}}})
 	e.GET("/2?a=b").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"params": map[string]string{}, "query": map[string][]string{"a": {"b"}}})
@@ -145,17 +162,20 @@ func TestRoute_fullmatch_params(t *testing.T) {
 	assertRoute(as, "GET", "/:user", fullMatch, handlerFunc, app.routes[1])
 	assertRoute(as, "GET", "/2/:user/not-param/:name", fullMatch, handlerFunc, app.routes[2])
 
-	assertBetweenRoute(as, app.routes[0], matchRouter("GET", "/1/user", app.routes)[0])
-	assertBetweenRoute(as, app.routes[1], matchRouter("GET", "/user", app.routes)[0])
-	assertBetweenRoute(as, app.routes[2], matchRouter("GET", "/2/user/not-param/name", app.routes)[0])
+	assertBetweenRoute(as, app.routes[0], lookupRoutes(app.tree, "GET", "/1/user")[0])
+	assertBetweenRoute(as, app.routes[1], lookupRoutes(app.tree, "GET", "/user")[0])
+	assertBetweenRoute(as, app.routes[2], lookupRoutes(app.tree, "GET", "/2/user/not-param/name")[0])
+
+	_, params := lookupPaths(app.tree, "GET", "/2/user/not-param/name")
+	as.Equal([]map[string]string{{"user": "user", "name": "name"}}, params)
 
-	as.Equal(app.routes[0].routePathReg.String(), "/1/(?P<user>.*)")
-	as.Equal(app.routes[1].routePathReg.String(), "/(?P<user>.*)")
-	as.Equal(app.routes[2].routePathReg.String(), "/2/(?P<user>.*)/not-param/(?P<name>.*)")
+	// the pattern is not compiled to regexp
+	as.NotPanics(func() { app.Get("/c++/:id", func(req *Req, res *Res) { renderParamQuery(req, res) }) })
 
 	e.GET("/1/user?a=b").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"params": map[string]string{"user": "user"}, "query": map[string][]string{"a": {"b"}}})
 	e.GET("/user?a=b").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"params": map[string]string{"user": "user"}, "query": map[string][]string{"a": {"b"}}})
 	e.GET("/2/user/not-param/name?a=b").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"params": map[string]string{"user": "user", "name": "name"}, "query": map[string][]string{"a": {"b"}}})
+	e.GET("/c++/1").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"params": map[string]string{"id": "1"}, "query": map[string][]string{}})
 }
 
 func TestRoute_prematch_use_method(t *testing.T) {
@@ -170,8 +190,8 @@ func TestRoute_prematch_use_method(t *testing.T) {
 	assertRoute(as, "ALL", "/1", preMatch, handlerFunc, app.routes[0])
 	assertRoute(as, "ALL", "/2", preMatch, handlerFuncNext, app.routes[1])
 
-	assertBetweenRoute(as, app.routes[0], matchRouter("ALL", "/1", app.routes)[0])
-	assertBetweenRoute(as, app.routes[1], matchRouter("ALL", "/2", app.routes)[0])
+	assertBetweenRoute(as, app.routes[0], lookupRoutes(app.tree, "ALL", "/1")[0])
+	assertBetweenRoute(as, app.routes[1], lookupRoutes(app.tree, "ALL", "/2")[0])
 
 	e.GET("/1?a=b").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"params": map[string]string{}, "query": map[string][]string{"a": {"b"}}})
 	e.GET("/1/2/3?a=b").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"params": map[string]string{}, "query": map[string][]string{"a": {"b"}}})
@@ -191,8 +211,8 @@ func TestRoute_prematch_all(t *testing.T) {
 	assertRoute(as, "ALL", "/1", onlyLastFull, handlerFunc, app.routes[0])
 	assertRoute(as, "ALL", "/2", onlyLastFull, handlerFuncNext, app.routes[1])
 
-	assertBetweenRoute(as, app.routes[0], matchRouter("ALL", "/1", app.routes)[0])
-	assertBetweenRoute(as, app.routes[1], matchRouter("ALL", "/2", app.routes)[0])
+	assertBetweenRoute(as, app.routes[0], lookupRoutes(app.tree, "ALL", "/1")[0])
+	assertBetweenRoute(as, app.routes[1], lookupRoutes(app.tree, "ALL", "/2")[0])
 
 	e.GET("/1?a=b").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"params": map[string]string{}, "query": map[string][]string{"a": {"b"}}})
 	e.GET("/1/2/3?a=b").Expect().Status(http.StatusNotFound)
@@ -222,9 +242,9 @@ func TestRoute_prematch_use_method_androuter(t *testing.T) {
 	assertRoute(as, "GET", "/1", fullMatch, handlerFunc, app.routes[1].children[0])
 	assertRoute(as, "GET", "/2", fullMatch, handlerFunc, app.routes[1].children[1])
 
-	assertOneRoute(as, "GET", "/no-sub", fullMatch, handlerFunc, matchRouter("GET", "/no-sub", app.routes))
-	assertOneRoute(as, "GET", "/main/1", fullMatch, handlerFunc, matchRouter("GET", "/main/1", app.routes))
-	assertOneRoute(as, "GET", "/main/2", fullMatch, handlerFunc, matchRouter("GET", "/main/2", app.routes))
+	assertOneRoute(as, "GET", "/no-sub", fullMatch, handlerFunc, lookupRoutes(app.tree, "GET", "/no-sub"))
+	assertOneRoute(as, "GET", "/main/1", fullMatch, handlerFunc, lookupRoutes(app.tree, "GET", "/main/1"))
+	assertOneRoute(as, "GET", "/main/2", fullMatch, handlerFunc, lookupRoutes(app.tree, "GET", "/main/2"))
 
 	e.GET("/no-sub?a=b").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"params": map[string]string{}, "query": map[string][]string{"a": {"b"}}})
 	e.GET("/main/1?a=b").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"params": map[string]string{}, "query": map[string][]string{"a": {"b"}}})
@@ -253,9 +273,9 @@ func TestRoute_prematch_all_method_androuter(t *testing.T) {
 	assertRoute(as, "GET", "/1", fullMatch, handlerFunc, app.routes[1].children[0])
 	assertRoute(as, "GET", "/2", fullMatch, handlerFunc, app.routes[1].children[1])
 
-	assertOneRoute(as, "GET", "/no-sub", fullMatch, handlerFunc, matchRouter("GET", "/no-sub", app.routes))
-	assertOneRoute(as, "GET", "/main/1", fullMatch, handlerFunc, matchRouter("GET", "/main/1", app.routes))
-	assertOneRoute(as, "GET", "/main/2", fullMatch, handlerFunc, matchRouter("GET", "/main/2", app.routes))
+	assertOneRoute(as, "GET", "/no-sub", fullMatch, handlerFunc, lookupRoutes(app.tree, "GET", "/no-sub"))
+	assertOneRoute(as, "GET", "/main/1", fullMatch, handlerFunc, lookupRoutes(app.tree, "GET", "/main/1"))
+	assertOneRoute(as, "GET", "/main/2", fullMatch, handlerFunc, lookupRoutes(app.tree, "GET", "/main/2"))
 
 	e.GET("/no-sub?a=b").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"params": map[string]string{}, "query": map[string][]string{"a": {"b"}}})
 	e.GET("/main/1?a=b").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"params": map[string]string{}, "query": map[string][]string{"a": {"b"}}})
@@ -286,14 +306,13 @@ func TestRoute_prematch_use_params(t *testing.T) {
 	assertRoute(as, "GET", "/1/:name1", fullMatch, handlerFunc, app.routes[1].children[0])
 	assertRoute(as, "GET", "/2/:name2", fullMatch, handlerFunc, app.routes[1].children[1])
 
-	assertOneRoute(as, "GET", "/no-sub/:name0", fullMatch, handlerFunc, matchRouter("GET", "/no-sub/name0", app.routes))
-	assertOneRoute(as, "GET", "/main/1/:name1", fullMatch, handlerFunc, matchRouter("GET", "/main/1/name1", app.routes))
-	assertOneRoute(as, "GET", "/main/2/:name2", fullMatch, handlerFunc, matchRouter("GET", "/main/2/name2", app.routes))
+	assertOneRoute(as, "GET", "/no-sub/:name0", fullMatch, handlerFunc, lookupRoutes(app.tree, "GET", "/no-sub/name0"))
+	assertOneRoute(as, "GET", "/main/1/:name1", fullMatch, handlerFunc, lookupRoutes(app.tree, "GET", "/main/1/name1"))
+	assertOneRoute(as, "GET", "/main/2/:name2", fullMatch, handlerFunc, lookupRoutes(app.tree, "GET", "/main/2/name2"))
 
-	as.Equal(app.routes[0].routePathReg.String(), "/no-sub/(?P<name0>.*)")
-	as.Nil(app.routes[1].routePathReg)
-	as.Equal(app.routes[1].children[0].routePathReg.String(), "/main/1/(?P<name1>.*)")
-	as.Equal(app.routes[1].children[1].routePathReg.String(), "/main/2/(?P<name2>.*)")
+	paths, params := lookupPaths(app.tree, "GET", "/main/2/name2")
+	as.Equal([]string{"/main/2/:name2"}, paths)
+	as.Equal([]map[string]string{{"name2": "name2"}}, params)
 
 	e.GET("/no-sub/name0?a=b").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"params": map[string]string{"name0": "name0"}, "query": map[string][]string{"a": {"b"}}})
 	e.GET("/main/1/name1?a=b").Expect().Status(http.StatusOK).JSON().Equal(map[string]interface{}{"params": map[string]string{"name1": "name1"}, "query": map[string][]string{"a": {"b"}}})
@@ -305,12 +324,68 @@ func TestRoute_function_next_mutil_errors(t *testing.T) {
 	defer ts.Close()
 
 	app.Use("/0", func(req *Req, res *Res, next Next) { next(); res.Send("x") })
-	app.Use("/1", func(req *Req, res *Res, next Next) { next("1") })
-	app.Use("/2", func(req *Req, res *Res, next Next) { next("1", "2") })
+	app.Use("/1", func(req *Req, res *Res, next Next) { next(errors.New("1")) })
+	app.Use("/2", func(req *Req, res *Res, next Next) { next(errors.New("1"), errors.New("2")) })
+	app.Use("/3", func(req *Req, res *Res, next Next) { next("1") })
+	app.Use("/nil", func(req *Req, res *Res, next Next) {
+		var err error
+		next(err)
+	})
+	app.Get("/nil", func(req *Req, res *Res) { res.Send("nil") })
+	app.Use("/nil-error", func(req *Req, res *Res, next Next) { next(nil, errors.New("1")) })
 
 	e.GET("/0").Expect().Status(http.StatusOK)
+	e.GET("/1").Expect().Status(http.StatusInternalServerError).Text().Equal("Internal Server Error")
+	e.GET("/nil").Expect().Status(http.StatusOK).Text().Equal("nil")
+
+	// the message of error is not responded, get it by the error handler
+	app.SetErrorHandler(func(err error, req *Req, res *Res) {
+		res.Status(http.StatusInternalServerError).Send(err.Error())
+	})
 	e.GET("/1").Expect().Status(http.StatusInternalServerError).Text().Equal("1")
 	e.GET("/2").Expect().Status(http.StatusInternalServerError).Text().Equal("1, 2")
+	e.GET("/3").Expect().Status(http.StatusInternalServerError).Text().Equal(`next only accept "route", "router" or error, but get []interface {}{"1"}`)
+	e.GET("/nil-error").Expect().Status(http.StatusInternalServerError).Text().Equal("1")
+}
+
+func TestRoute_next_route_router(t *testing.T) {
+	app, ts, e, _ := newTestServer(t)
+	defer ts.Close()
+
+	var trace []string
+	mid := func(name string, arg ...interface{}) HandlerFuncNext {
+		return func(req *Req, res *Res, next Next) {
+			trace = append(trace, name)
+			next(arg...)
+		}
+	}
+
+	router := NewRouter()
+	router.Use(mid("router-1"))
+	router.Use("/skip", mid("router-2", "router"))
+	router.Use(mid("router-3"))
+	sub := NewRouter()
+	sub.Use(mid("sub-1", "router"))
+	sub.Use(mid("sub-2"))
+	router.Use("/sub", sub)
+	router.Use(mid("router-4"))
+	app.Use(mid("app-1", "route"))
+	app.Use("/main", router)
+	app.Use(mid("app-2"))
+	app.Use("/exit", mid("app-3", "router"))
+	app.Use(mid("app-4"))
+
+	for path, expected := range map[string][]string{
+		"/main":          {"app-1", "router-1", "router-3", "router-4", "app-2", "app-4"},
+		"/main/skip":     {"app-1", "router-1", "router-2", "app-2", "app-4"},
+		"/main/sub":      {"app-1", "router-1", "router-3", "sub-1", "router-4", "app-2", "app-4"},
+		"/main/skip/sub": {"app-1", "router-1", "router-2", "app-2", "app-4"},
+		"/exit":          {"app-1", "app-2", "app-3"},
+	} {
+		trace = nil
+		e.GET(path).Expect().Status(http.StatusNotFound)
+		assert.Equal(t, expected, trace, path)
+	}
 }
 
 func TestFixMatchType(t *testing.T) {
@@ -338,3 +413,210 @@ func TestRoute_Group(t *testing.T) {
 	e.GET("/group/1").Expect().Status(http.StatusOK).Text().Equal("1")
 	e.GET("/group/sub/2").Expect().Status(http.StatusOK).Text().Equal("2")
 }
+
+func TestMethodNotAllowed(t *testing.T) {
+	app, ts, e, _ := newTestServer(t)
+	defer ts.Close()
+
+	app.Use(func(req *Req, res *Res, next Next) { next() })
+	app.Get("/a", func(req *Req, res *Res) { res.Send("get") })
+	app.Post("/a", func(req *Req, res *Res) { res.Send("post") })
+	app.Get("/b/:id", func(req *Req, res *Res) { res.Send("get") })
+	app.Options("/b/:id", func(req *Req, res *Res) { res.Send("options") })
+	app.All("/c", func(req *Req, res *Res) { res.Send("all") })
+
+	e.GET("/a").Expect().Status(http.StatusOK).Text().Equal("get")
+	e.PUT("/a").Expect().Status(http.StatusMethodNotAllowed).Header("Allow").Equal("GET, POST, HEAD, OPTIONS")
+	e.OPTIONS("/a").Expect().Status(http.StatusNoContent).Header("Allow").Equal("GET, POST, HEAD, OPTIONS")
+	e.DELETE("/b/1").Expect().Status(http.StatusMethodNotAllowed).Header("Allow").Equal("GET, OPTIONS, HEAD")
+	e.OPTIONS("/b/1").Expect().Status(http.StatusOK).Text().Equal("options")
+	e.DELETE("/c").Expect().Status(http.StatusOK).Text().Equal("all")
+	e.DELETE("/d").Expect().Status(http.StatusNotFound)
+
+	app.SetMethodNotAllowedHandler(func(req *Req, res *Res) {
+		res.Status(http.StatusMethodNotAllowed).JSON(req.AllowedMethods())
+	})
+	app.SetOptionsHandler(nil)
+	app.SetImplicitHead(false)
+	e.PUT("/a").Expect().Status(http.StatusMethodNotAllowed).Header("Allow").Equal("GET, POST")
+	e.PUT("/a").Expect().Status(http.StatusMethodNotAllowed).JSON().Equal([]string{"GET", "POST"})
+	e.OPTIONS("/a").Expect().Status(http.StatusMethodNotAllowed).Header("Allow").Equal("GET, POST")
+
+	app.SetMethodNotAllowedHandler(nil)
+	e.PUT("/a").Expect().Status(http.StatusNotFound)
+}
+
+func TestImplicitHead(t *testing.T) {
+	app, ts, e, _ := newTestServer(t)
+	defer ts.Close()
+
+	app.Get("/", func(req *Req, res *Res) {
+		res.AddHeader("X-Method", req.Method)
+		res.Status(http.StatusAccepted).Send("Hello World")
+	})
+	app.Get("/json", func(req *Req, res *Res) { res.JSON([]string{"a"}) })
+	app.Get("/head", func(req *Req, res *Res) { res.Send("get") })
+	app.Head("/head", func(req *Req, res *Res) { res.AddHeader("X-Head", "1"); res.Send("head") })
+
+	e.GET("/").Expect().Status(http.StatusAccepted).Text().Equal("Hello World")
+	r := e.HEAD("/").Expect().Status(http.StatusAccepted)
+	r.Header("X-Method").Equal("HEAD")
+	r.Header("Content-Length").Equal("11")
+	r.Body().Empty()
+	r = e.HEAD("/json").Expect().Status(http.StatusOK)
+	r.Header("Content-Type").Equal("application/json")
+	r.Header("Content-Length").Equal("5")
+	r.Body().Empty()
+	e.HEAD("/head").Expect().Status(http.StatusOK).Header("X-Head").Equal("1")
+	e.HEAD("/not-exist").Expect().Status(http.StatusNotFound)
+
+	app.SetImplicitHead(false)
+	e.HEAD("/").Expect().Status(http.StatusMethodNotAllowed)
+	e.HEAD("/head").Expect().Status(http.StatusOK).Header("X-Head").Equal("1")
+}
+
+func TestRoute_method_middleware(t *testing.T) {
+	app, ts, e, as := newTestServer(t)
+	defer ts.Close()
+
+	auth := func(req *Req, res *Res, next Next) {
+		if req.Query["token"] == nil {
+			res.Status(http.StatusUnauthorized).Send("unauthorized")
+			return
+		}
+		req.AddContext("user", req.Query["token"][0])
+		next()
+	}
+	wrap := func(req *Req, res *Res, next Next) {
+		res.AddHeader("X-Wrap", "before")
+		next()
+		res.AddHeader("X-Wrap", "after")
+	}
+	skip := func(req *Req, res *Res, next Next) {
+		if req.Query["skip"] != nil {
+			next("route")
+			return
+		}
+		next()
+	}
+	setHeader := func(req *Req, res *Res) { res.AddHeader("X-Func", "1") }
+
+	app.Get("/user", auth, wrap, setHeader, func(req *Req, res *Res) { res.Send(req.GetContext("user")) })
+	app.Post("/skip", skip, func(req *Req, res *Res) { res.Send("not skipped") })
+	app.Post("/skip", func(req *Req, res *Res) { res.Send("skipped") })
+	as.Panics(func() { app.Get("/empty") })
+	as.Panics(func() { app.Get("/wrong", "string", func(req *Req, res *Res) {}) })
+
+	e.GET("/user").Expect().Status(http.StatusUnauthorized).Text().Equal("unauthorized")
+	r := e.GET("/user").WithQuery("token", "chyroc").Expect().Status(http.StatusOK)
+	r.Text().Equal("chyroc")
+	r.Header("X-Wrap").Equal("before")
+	r.Header("X-Func").Equal("1")
+	e.POST("/skip").Expect().Status(http.StatusOK).Text().Equal("not skipped")
+	e.POST("/skip").WithQuery("skip", "1").Expect().Status(http.StatusOK).Text().Equal("skipped")
+
+	routes := app.Routes()
+	as.Len(routes[0].Middleware, 3)
+	as.Equal("github.com/Chyroc/gor.TestRoute_method_middleware.func1", routes[0].Middleware[0])
+}
+
+func TestRoute_error_handler(t *testing.T) {
+	app, ts, e, as := newTestServer(t)
+	defer ts.Close()
+
+	var logs []string
+	sub := NewGor()
+	sub.Get("/fail", func(req *Req, res *Res, next Next) { next(errors.New("sub")) })
+
+	app.Get("/ok", func(req *Req, res *Res) { res.Send("ok") })
+	app.Get("/fail", func(req *Req, res *Res, next Next) { next(errors.New("fail")) })
+	app.Get("/transform", func(req *Req, res *Res, next Next) { next(errors.New("transform")) })
+	app.Get("/recover", func(req *Req, res *Res, next Next) { next(errors.New("recover")) })
+	app.Get("/chain", func(req *Req, res *Res, next Next) { next(errors.New("chain")) },
+		func(err error, req *Req, res *Res, next Next) { res.Send("route " + err.Error()) },
+		func(req *Req, res *Res) { res.Send("not called") })
+	app.Param("id", func(req *Req, res *Res, next Next, value string) { next(errors.New("param " + value)) })
+	app.Get("/param/:id", func(req *Req, res *Res) { res.Send("not called") })
+	app.Use("/sub", sub)
+
+	app.Use(func(err error, req *Req, res *Res, next Next) {
+		logs = append(logs, err.Error())
+		next(err)
+	})
+	app.Use("/transform", func(err error, req *Req, res *Res, next Next) {
+		next(errors.New("transformed"))
+	})
+	app.Use("/recover", ErrorHandlerFunc(func(err error, req *Req, res *Res, next Next) {
+		next()
+	}))
+	app.Get("/recover", func(req *Req, res *Res) { res.Send("recovered") })
+	app.Use(func(err error, req *Req, res *Res, next Next) {
+		res.Status(http.StatusBadRequest).Send("handled " + err.Error())
+	})
+
+	e.GET("/ok").Expect().Status(http.StatusOK).Text().Equal("ok")
+	as.Len(logs, 0)
+	e.GET("/fail").Expect().Status(http.StatusBadRequest).Text().Equal("handled fail")
+	e.GET("/transform").Expect().Status(http.StatusBadRequest).Text().Equal("handled transformed")
+	e.GET("/recover").Expect().Status(http.StatusOK).Text().Equal("recovered")
+	e.GET("/chain").Expect().Status(http.StatusOK).Text().Equal("route chain")
+	e.GET("/param/1").Expect().Status(http.StatusBadRequest).Text().Equal("handled param 1")
+	e.GET("/sub/fail").Expect().Status(http.StatusBadRequest).Text().Equal("handled sub")
+	as.Equal([]string{"fail", "transform", "recover", "param 1", "sub"}, logs)
+	e.GET("/not-found").Expect().Status(http.StatusNotFound)
+
+	only := NewGor()
+	only.Get("/", func(req *Req, res *Res, next Next) { next(errors.New("no handler")) })
+	only.Use(func(err error, req *Req, res *Res, next Next) { next(err) })
+	w := httptest.NewRecorder()
+	only.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	as.Equal(http.StatusInternalServerError, w.Code)
+	as.Equal("Internal Server Error", w.Body.String())
+}
+
+type statusTestError int
+
+func (e statusTestError) Error() string   { return http.StatusText(int(e)) }
+func (e statusTestError) StatusCode() int { return int(e) }
+
+func TestRoute_handler_with_error(t *testing.T) {
+	app, ts, e, as := newTestServer(t)
+	defer ts.Close()
+
+	app.Use("/guard", func(req *Req, res *Res, next Next) error {
+		if req.Query["token"] == nil {
+			return statusTestError(http.StatusUnauthorized)
+		}
+		next()
+		return nil
+	})
+	app.Get("/guard", func(req *Req, res *Res) error {
+		res.Send("guarded")
+		return nil
+	})
+	app.Get("/plain", func(req *Req, res *Res) error { return errors.New("plain") })
+	app.Get("/wrapped", HandlerFuncWithError(func(req *Req, res *Res) error {
+		return fmt.Errorf("wrapped: %w", statusTestError(http.StatusConflict))
+	}))
+	app.Get("/next", func(req *Req, res *Res) error { return nil }, func(req *Req, res *Res) { res.Send("next") })
+	app.Get("/handled", func(req *Req, res *Res) error { return errors.New("handled") },
+		func(err error, req *Req, res *Res, next Next) { res.Status(http.StatusTeapot).Send(err.Error()) })
+
+	e.GET("/guard").Expect().Status(http.StatusUnauthorized).Text().Equal("Unauthorized")
+	e.GET("/guard").WithQuery("token", "1").Expect().Status(http.StatusOK).Text().Equal("guarded")
+	e.GET("/plain").Expect().Status(http.StatusInternalServerError).Text().Equal("Internal Server Error")
+	e.GET("/wrapped").Expect().Status(http.StatusConflict).Text().Equal("wrapped: Conflict")
+	e.GET("/next").Expect().Status(http.StatusOK).Text().Equal("next")
+	e.GET("/handled").Expect().Status(http.StatusTeapot).Text().Equal("handled")
+
+	var errs []error
+	app.SetErrorHandler(func(err error, req *Req, res *Res) {
+		errs = append(errs, err)
+		res.Status(http.StatusBadGateway).JSON(map[string]string{"error": err.Error()})
+	})
+	e.GET("/plain").Expect().Status(http.StatusBadGateway).JSON().Equal(map[string]string{"error": "plain"})
+	as.Len(errs, 1)
+
+	app.SetErrorHandler(nil)
+	e.GET("/plain").Expect().Status(http.StatusInternalServerError).Text().Equal("Internal Server Error")
+}
@@ -0,0 +1,145 @@
+package gor
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+)
+
+// RouteInfo is the information of a registered route, nested routes of Router and sub application are flattened with full pattern
+type RouteInfo struct {
+	Method string
+	// Host is the host pattern of Route.Host, empty for the routes of any host
+	Host    string
+	Pattern string
+	// Match is `use` (prefix match), `all` or `handler`
+	Match   string
+	Name    string
+	Handler string
+	// Middleware is the names of the `use` routes which run before the route
+	Middleware []string
+}
+
+// Routes return all the routes in registration order
+func (r *Route) Routes() []RouteInfo {
+	var infos []RouteInfo
+	for _, l := range r.tree.leaves {
+		if l.route.notFound != nil {
+			continue
+		}
+		info := RouteInfo{
+			Method:  l.route.method,
+			Host:    l.hostOf(),
+			Pattern: l.path,
+			Match:   l.route.matchType.String(),
+			Name:    l.name(),
+			Handler: l.route.handlerName(),
+		}
+		for _, v := range r.tree.leaves[:l.order] {
+			if v.prefix && v.route.notFound == nil && (v.hostOf() == "" || v.hostOf() == info.Host) && (v.route.method == "ALL" || v.route.method == l.route.method) && coverSegments(v.segments, l.segments) {
+				info.Middleware = append(info.Middleware, v.route.handlerName())
+			}
+		}
+		for _, v := range l.route.chain {
+			info.Middleware = append(info.Middleware, v.name())
+		}
+		if l.route.app != nil {
+			infos = append(infos, l.subRoutes(info)...)
+			continue
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// subRoutes return the routes of the sub application mounted by l, under the mount pattern,
+// with the host and the middleware of the mount route (mount)
+func (l *leaf) subRoutes(mount RouteInfo) []RouteInfo {
+	var infos []RouteInfo
+	for _, v := range l.route.app.Routes() {
+		v.Pattern = joinRoutePath(mount.Pattern, v.Pattern)
+		if v.Host == "" {
+			v.Host = mount.Host
+		}
+		if v.Name != "" {
+			v.Name = mount.Name + v.Name
+		}
+		v.Middleware = append(append([]string(nil), mount.Middleware...), v.Middleware...)
+		infos = append(infos, v)
+	}
+	return infos
+}
+
+// PrintRoutes print the routes as an aligned table, empty cell is printed as `-`
+//
+// pattern of the route mounted by Host is prefixed with the host pattern
+func (r *Route) PrintRoutes(w io.Writer) error {
+	cell := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATTERN\tMATCH\tNAME\tHANDLER\tMIDDLEWARE")
+	for _, v := range r.Routes() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", v.Method, v.Host+v.Pattern, v.Match, cell(v.Name), cell(v.Handler), cell(strings.Join(v.Middleware, ", ")))
+	}
+	return tw.Flush()
+}
+
+func (m matchType) String() string {
+	switch m {
+	case preMatch:
+		return "use"
+	case onlyLastFull:
+		return "all"
+	}
+	return "handler"
+}
+
+func (r *route) handlerName() string {
+	return r.step(len(r.chain)).name()
+}
+
+func funcName(f interface{}) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return ""
+}
+
+// coverSegments report whether the prefix pattern match all the request paths which match the target pattern
+func coverSegments(prefix, target []segment) bool {
+	prefix = prefix[:requiredSegments(prefix)]
+	if len(prefix) > len(target) {
+		return false
+	}
+
+	for i, p := range prefix {
+		t := target[i]
+		switch p.kind {
+		case staticSegment:
+			if t.kind != staticSegment || t.value != p.value {
+				return false
+			}
+		case paramSegment:
+			if t.kind == catchAllSegment || t.optional {
+				return false
+			}
+			if p.matcher == nil || (t.kind == paramSegment && t.constraint == p.constraint) {
+				continue
+			}
+			if _, ok := p.matcher(t.value); t.kind != staticSegment || !ok {
+				return false
+			}
+		case catchAllSegment:
+			return !t.optional
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package gor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func routesTestHandler(req *Req, res *Res) {}
+
+func routesTestMiddleware(req *Req, res *Res, next Next) { next() }
+
+func TestRoutes(t *testing.T) {
+	as := assert.New(t)
+
+	app := NewGor()
+	app.Use(routesTestMiddleware)
+	app.Get("/users/:id<int>", routesTestHandler).Name("user")
+	app.Use("/users/:id(\\d+)", routesTestMiddleware)
+	app.Post("/users/:id", routesTestHandler)
+	router := NewRouter()
+	router.Use("/a", routesTestMiddleware)
+	router.Get("/a/b", routesTestHandler)
+	router.Get("/c", routesTestHandler)
+	app.All("/router", router)
+	app.All("/all", routesTestHandler)
+
+	as.Equal([]RouteInfo{
+		{Method: "ALL", Pattern: "/", Match: "use", Handler: "github.com/Chyroc/gor.routesTestMiddleware"},
+		{Method: "GET", Pattern: "/users/:id<int>", Match: "handler", Name: "user", Handler: "github.com/Chyroc/gor.routesTestHandler", Middleware: []string{"github.com/Chyroc/gor.routesTestMiddleware"}},
+		{Method: "ALL", Pattern: "/users/:id(\\d+)", Match: "use", Handler: "github.com/Chyroc/gor.routesTestMiddleware", Middleware: []string{"github.com/Chyroc/gor.routesTestMiddleware"}},
+		{Method: "POST", Pattern: "/users/:id", Match: "handler", Handler: "github.com/Chyroc/gor.routesTestHandler", Middleware: []string{"github.com/Chyroc/gor.routesTestMiddleware"}},
+		{Method: "ALL", Pattern: "/router/a", Match: "use", Handler: "github.com/Chyroc/gor.routesTestMiddleware", Middleware: []string{"github.com/Chyroc/gor.routesTestMiddleware"}},
+		{Method: "GET", Pattern: "/router/a/b", Match: "handler", Handler: "github.com/Chyroc/gor.routesTestHandler", Middleware: []string{"github.com/Chyroc/gor.routesTestMiddleware", "github.com/Chyroc/gor.routesTestMiddleware"}},
+		{Method: "GET", Pattern: "/router/c", Match: "handler", Handler: "github.com/Chyroc/gor.routesTestHandler", Middleware: []string{"github.com/Chyroc/gor.routesTestMiddleware"}},
+		{Method: "ALL", Pattern: "/all", Match: "all", Handler: "github.com/Chyroc/gor.routesTestHandler", Middleware: []string{"github.com/Chyroc/gor.routesTestMiddleware"}},
+	}, app.Routes())
+
+	buf := new(bytes.Buffer)
+	as.Nil(router.PrintRoutes(buf))
+	as.Equal(`METHOD  PATTERN  MATCH    NAME  HANDLER                                     MIDDLEWARE
+ALL     /a       use      -     github.com/Chyroc/gor.routesTestMiddleware  -
+GET     /a/b     handler  -     github.com/Chyroc/gor.routesTestHandler     github.com/Chyroc/gor.routesTestMiddleware
+GET     /c       handler  -     github.com/Chyroc/gor.routesTestHandler     -
+`, buf.String())
+}
+
+func TestRoutes_sub_application(t *testing.T) {
+	as := assert.New(t)
+
+	app := NewGor()
+	app.Use(routesTestMiddleware)
+	admin := NewGor()
+	admin.SetConflictPolicy(ConflictIgnore)
+	admin.Get("/users/:id", routesTestHandler).Name("user")
+	admin.Get("/users/:name", routesTestHandler)
+	router := NewRouter()
+	router.Use("/admin", admin)
+	app.Use("/orgs/:org", router)
+
+	as.Equal([]RouteInfo{
+		{Method: "ALL", Pattern: "/", Match: "use", Handler: "github.com/Chyroc/gor.routesTestMiddleware"},
+		{Method: "GET", Pattern: "/orgs/:org/admin/users/:id", Match: "handler", Name: "user", Handler: "github.com/Chyroc/gor.routesTestHandler", Middleware: []string{"github.com/Chyroc/gor.routesTestMiddleware"}},
+		{Method: "GET", Pattern: "/orgs/:org/admin/users/:name", Match: "handler", Handler: "github.com/Chyroc/gor.routesTestHandler", Middleware: []string{"github.com/Chyroc/gor.routesTestMiddleware"}},
+	}, app.Routes())
+	as.Equal([]RouteConflict{{
+		Kind:            ConflictAmbiguous,
+		Method:          "GET",
+		Pattern:         "/orgs/:org/admin/users/:name",
+		PreviousMethod:  "GET",
+		PreviousPattern: "/orgs/:org/admin/users/:id",
+	}}, app.Conflicts())
+}
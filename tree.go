@@ -0,0 +1,625 @@
+package gor
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type segmentKind uint8
+
+const (
+	staticSegment segmentKind = iota
+	paramSegment
+	catchAllSegment
+)
+
+// segment is one parsed segment of route pattern
+//
+// static   `users`
+// param    `:id`, `:id(\d+)` or `:id<int>` to constrain the value, `:id?` is optional
+// catchAll `*filepath`, match the rest of the path
+type segment struct {
+	kind     segmentKind
+	value    string
+	optional bool
+
+	constraint string
+	regexp     string
+	matcher    ParamMatcher
+}
+
+// rank is the precedence when several routes match one path, the lower the better
+func (s segment) rank() uint8 {
+	switch {
+	case s.kind == staticSegment:
+		return 0
+	case s.kind == paramSegment && s.matcher != nil:
+		return 1
+	case s.kind == paramSegment:
+		return 2
+	}
+	return 3
+}
+
+func parseSegment(seg string) (segment, error) {
+	switch seg[0] {
+	case ':':
+		s := segment{kind: paramSegment, value: seg[1:]}
+		if strings.HasSuffix(s.value, "?") {
+			s.value = s.value[:len(s.value)-1]
+			s.optional = true
+		}
+		if i := strings.IndexAny(s.value, "(<"); i >= 0 {
+			s.constraint = s.value[i:]
+			s.value = s.value[:i]
+
+			switch {
+			case len(s.constraint) > 2 && s.constraint[0] == '(' && strings.HasSuffix(s.constraint, ")"):
+				s.regexp = s.constraint[1 : len(s.constraint)-1]
+				matcher, err := regexpParamMatcher(s.regexp)
+				if err != nil {
+					return s, err
+				}
+				s.matcher = matcher
+			case len(s.constraint) > 2 && s.constraint[0] == '<' && strings.HasSuffix(s.constraint, ">"):
+				matcher := getParamMatcher(s.constraint[1 : len(s.constraint)-1])
+				if matcher == nil {
+					return s, fmt.Errorf("param matcher %s is not registered", s.constraint)
+				}
+				s.matcher = matcher
+			default:
+				return s, fmt.Errorf("param constraint %s is invalid", s.constraint)
+			}
+		}
+		s.value = strings.ToLower(s.value)
+		return s, nil
+	case '*':
+		return segment{kind: catchAllSegment, value: strings.ToLower(seg[1:])}, nil
+	}
+	return segment{kind: staticSegment, value: seg}, nil
+}
+
+// parsePattern parse and check route pattern
+//
+// catch-all param must be the last segment, and only the last segments can be optional
+func parsePattern(pattern string) []segment {
+	var segments []segment
+	optional := false
+	for _, v := range splitPath(pattern) {
+		s, err := parseSegment(v)
+		if err != nil {
+			panic(fmt.Sprintf("pattern invalid: %s, %s", pattern, err))
+		}
+		if s.kind != staticSegment && s.value == "" {
+			panic(fmt.Sprintf("param name cannot be empty: %s", pattern))
+		}
+		if len(segments) > 0 && segments[len(segments)-1].kind == catchAllSegment {
+			panic(fmt.Sprintf("catch-all param must be the last segment: %s", pattern))
+		}
+		if optional && !s.optional {
+			panic(fmt.Sprintf("only the last segments can be optional: %s", pattern))
+		}
+		optional = s.optional
+		segments = append(segments, s)
+	}
+	return segments
+}
+
+// node is one path segment of the routing tree
+type node struct {
+	static map[string]*node
+	// folded is the static children by the lower case value, for case-insensitive lookup
+	folded   map[string][]*node
+	params   []*node
+	catchAll *node
+
+	// constraint of param node, matcher is nil when param is not constrained
+	constraint string
+	matcher    ParamMatcher
+
+	leaves []*leaf
+}
+
+// leaf is a flattened route stored at the node of its last segment
+type leaf struct {
+	order  int
+	route  *route
+	path   string
+	prefix bool
+	// the route has optional segments, so it is inserted more than once
+	optional bool
+
+	// mount routes (router, group) of the route, from outer to inner
+	mounts []*route
+	// host patterns of the mount routes, the request host must match all of them
+	hosts []*hostPattern
+	// owners of the param handlers of keys
+	owners []*paramSet
+	// segments of path, it is only set in the leaf with all optional segments
+	segments []segment
+
+	// names and matchers of the param segments of path, in order
+	keys     []string
+	matchers []ParamMatcher
+	// ranks of the segments of path, to compare the precedence
+	ranks []uint8
+}
+
+// name return the name of route, prefixed with the names of its mount routes
+func (l *leaf) name() string {
+	if l.route.name == "" {
+		return ""
+	}
+
+	name := ""
+	for _, v := range l.mounts {
+		name += v.name
+	}
+	return name + l.route.name
+}
+
+// moreSpecific report whether l should be preferred to other when both match one path
+func (l *leaf) moreSpecific(other *leaf) bool {
+	for i := 0; i < len(l.ranks) && i < len(other.ranks); i++ {
+		if l.ranks[i] != other.ranks[i] {
+			return l.ranks[i] < other.ranks[i]
+		}
+	}
+	return false
+}
+
+type tree struct {
+	root   *node
+	leaves []*leaf
+	count  int
+
+	// onConflict is called when the inserted route conflict with the previous routes, it is nil for Router
+	onConflict func(RouteConflict)
+
+	// app is the Gor which own the tree, it is nil for Router
+	app *Gor
+
+	// strictSlash: `/a/` do not match the route `/a`, caseInsensitive: `/A` match the route `/a`
+	strictSlash     bool
+	caseInsensitive bool
+}
+
+func newTree() *tree {
+	return &tree{root: &node{}}
+}
+
+// insert flatten r (and its children, with their full path) into the tree
+func (t *tree) insert(prefix string, mounts []*route, r *route) {
+	path := joinRoutePath(prefix, r.routePath)
+	if len(r.children) > 0 {
+		childMounts := append(append([]*route(nil), mounts...), r)
+		for _, v := range r.children {
+			t.insert(path, childMounts, v)
+		}
+		if r.notFound != nil {
+			// the leaf which serve the not found handler, it is after all the children
+			t.insert(path, childMounts, &route{method: "ALL", matchType: preMatch, routePath: "/", notFound: r.notFound})
+		}
+		return
+	}
+
+	segments := parsePattern(path)
+	var hosts []*hostPattern
+	var hostKeys []string
+	var hostMatchers []ParamMatcher
+	for _, v := range mounts {
+		if v.host != nil {
+			hosts = append(hosts, v.host)
+			hostKeys = append(hostKeys, v.host.keys...)
+			hostMatchers = append(hostMatchers, v.host.matchers...)
+		}
+	}
+
+	if t.onConflict != nil {
+		l := &leaf{route: r, path: path, prefix: r.matchType == preMatch, hosts: hosts, segments: segments}
+		for _, v := range t.conflicts(t.leaves, l) {
+			t.onConflict(v)
+		}
+	}
+
+	order := t.count
+	t.count++
+	if r.app != nil && t.app != nil {
+		r.app.mounted(t.app, path)
+	}
+
+	owners := paramOwners(mounts, r)
+
+	// `/posts/:id?` is inserted as `/posts` and `/posts/:id`
+	required := requiredSegments(segments)
+	for i := required; i <= len(segments); i++ {
+		l := t.insertSegments(segments[:i])
+		l.order = order
+		l.route = r
+		l.path = path
+		l.prefix = r.matchType == preMatch
+		l.optional = required < len(segments)
+		l.mounts = mounts
+		if len(hosts) > 0 {
+			l.hosts = hosts
+			l.keys = append(append([]string(nil), hostKeys...), l.keys...)
+			l.matchers = append(append([]ParamMatcher(nil), hostMatchers...), l.matchers...)
+		}
+		l.owners = owners[:len(l.keys)]
+		if i == len(segments) {
+			l.segments = segments
+			t.leaves = append(t.leaves, l)
+		}
+	}
+}
+
+// named return the first leaf with the name
+func (t *tree) named(name string) *leaf {
+	for _, l := range t.leaves {
+		if l.name() == name {
+			return l
+		}
+	}
+	return nil
+}
+
+func (t *tree) insertSegments(segments []segment) *leaf {
+	l := &leaf{}
+	n := t.root
+	for _, seg := range segments {
+		l.ranks = append(l.ranks, seg.rank())
+
+		switch seg.kind {
+		case paramSegment:
+			l.keys = append(l.keys, seg.value)
+			l.matchers = append(l.matchers, seg.matcher)
+			n = n.paramChild(seg)
+		case catchAllSegment:
+			l.keys = append(l.keys, seg.value)
+			l.matchers = append(l.matchers, nil)
+			if n.catchAll == nil {
+				n.catchAll = &node{}
+			}
+			n = n.catchAll
+		default:
+			if n.static == nil {
+				n.static = make(map[string]*node)
+			}
+			child, ok := n.static[seg.value]
+			if !ok {
+				child = &node{}
+				n.static[seg.value] = child
+				if n.folded == nil {
+					n.folded = make(map[string][]*node)
+				}
+				lower := strings.ToLower(seg.value)
+				n.folded[lower] = append(n.folded[lower], child)
+			}
+			n = child
+		}
+	}
+
+	n.leaves = append(n.leaves, l)
+	return l
+}
+
+// paramChild return the param child of n for seg, constrained params are tried before the others
+func (n *node) paramChild(seg segment) *node {
+	for _, v := range n.params {
+		if v.constraint == seg.constraint {
+			return v
+		}
+	}
+
+	child := &node{constraint: seg.constraint, matcher: seg.matcher}
+	i := len(n.params)
+	if child.matcher != nil {
+		for i > 0 && n.params[i-1].matcher == nil {
+			i--
+		}
+	}
+	n.params = append(n.params, nil)
+	copy(n.params[i+1:], n.params[i:])
+	n.params[i] = child
+	return child
+}
+
+type match struct {
+	leaf  *leaf
+	start int
+}
+
+// matcher hold the state of one lookup, it is reused by matcherPool
+type matcher struct {
+	method  string
+	host    string
+	path    string
+	segs    []string
+	offsets []int
+	stack   []string
+
+	values  []string
+	matches []match
+
+	// methods of the handlers which match the path but not the method
+	allowed []string
+
+	// slash report whether path has trailing slash, strict and fold are the policies of the tree
+	slash  bool
+	strict bool
+	fold   bool
+}
+
+var matcherPool = sync.Pool{
+	New: func() interface{} {
+		return &matcher{}
+	},
+}
+
+func acquireMatcher() *matcher {
+	return matcherPool.Get().(*matcher)
+}
+
+func releaseMatcher(m *matcher) {
+	m.host = ""
+	m.path = ""
+	m.slash = false
+	m.strict = false
+	m.fold = false
+	m.segs = m.segs[:0]
+	m.offsets = m.offsets[:0]
+	m.stack = m.stack[:0]
+	m.values = m.values[:0]
+	m.matches = m.matches[:0]
+	m.allowed = m.allowed[:0]
+	matcherPool.Put(m)
+}
+
+// lookup collect every leaf matching (method, requestPath) into m.matches
+//
+// matches are in registration order, but when several handlers (not prefix match) match without prefix match between them,
+// they are sorted by precedence (static > constrained param > param > catch-all) between themselves
+func (t *tree) lookup(m *matcher, method, requestPath string) {
+	m.method = method
+	m.strict = t.strictSlash
+	m.fold = t.caseInsensitive
+	m.split(requestPath)
+	m.stack = m.stack[:0]
+	m.values = m.values[:0]
+	m.matches = m.matches[:0]
+	m.allowed = m.allowed[:0]
+
+	m.walk(t.root, 0)
+
+	// insertion sort, the result is small and mostly sorted
+	matches := m.matches
+	for i := 1; i < len(matches); i++ {
+		for j := i; j > 0 && matches[j].leaf.order < matches[j-1].leaf.order; j-- {
+			matches[j], matches[j-1] = matches[j-1], matches[j]
+		}
+	}
+	// a handler is never moved ahead of the prefix match (Use) registered before it, the middleware must run first
+	for i := 1; i < len(matches); i++ {
+		if matches[i].leaf.prefix {
+			continue
+		}
+		for j := i; j > 0 && !matches[j-1].leaf.prefix && matches[j].leaf.moreSpecific(matches[j-1].leaf); j-- {
+			matches[j], matches[j-1] = matches[j-1], matches[j]
+		}
+	}
+}
+
+// split split path to m.segs, skip empty segment
+func (m *matcher) split(path string) {
+	m.path = path
+	m.slash = len(path) > 1 && path[len(path)-1] == '/'
+	m.segs = m.segs[:0]
+	m.offsets = m.offsets[:0]
+	for i := 0; i < len(path); {
+		if path[i] == '/' {
+			i++
+			continue
+		}
+		j := strings.IndexByte(path[i:], '/')
+		if j < 0 {
+			j = len(path) - i
+		}
+		m.segs = append(m.segs, path[i:i+j])
+		m.offsets = append(m.offsets, i)
+		i += j
+	}
+}
+
+func (m *matcher) walk(n *node, depth int) {
+	end := depth == len(m.segs)
+	for _, l := range n.leaves {
+		if !l.prefix && (!end || (m.strict && l.route.trailingSlash != m.slash)) {
+			continue
+		}
+		start := len(m.values)
+		if !m.matchHosts(l) {
+			m.values = m.values[:start]
+			continue
+		}
+		if l.route.method != "ALL" && l.route.method != m.method {
+			m.values = m.values[:start]
+			if !l.prefix {
+				m.allow(l.route.method)
+			}
+			continue
+		}
+		m.add(l, start)
+	}
+	if end {
+		return
+	}
+
+	seg := m.segs[depth]
+	if m.fold {
+		for _, child := range n.folded[strings.ToLower(seg)] {
+			m.walk(child, depth+1)
+		}
+	} else if child, ok := n.static[seg]; ok {
+		m.walk(child, depth+1)
+	}
+	for _, child := range n.params {
+		if child.matcher != nil {
+			if _, ok := child.matcher(seg); !ok {
+				continue
+			}
+		}
+		m.stack = append(m.stack, seg)
+		m.walk(child, depth+1)
+		m.stack = m.stack[:len(m.stack)-1]
+	}
+	if n.catchAll != nil {
+		last := len(m.segs) - 1
+		m.stack = append(m.stack, m.path[m.offsets[depth]:m.offsets[last]+len(m.segs[last])])
+		m.walk(n.catchAll, len(m.segs))
+		m.stack = m.stack[:len(m.stack)-1]
+	}
+}
+
+// add add l to matches, the values of its host params start at start of m.values
+func (m *matcher) add(l *leaf, start int) {
+	mt := match{leaf: l, start: start}
+	m.values = append(m.values, m.stack...)
+
+	// a prefix route with optional segments can match at several depth, keep the deepest one
+	if l.optional && l.prefix {
+		for i := range m.matches {
+			if m.matches[i].leaf.order == l.order {
+				m.matches[i] = mt
+				return
+			}
+		}
+	}
+	m.matches = append(m.matches, mt)
+}
+
+func (m *matcher) allow(method string) {
+	for _, v := range m.allowed {
+		if v == method {
+			return
+		}
+	}
+	m.allowed = append(m.allowed, method)
+}
+
+// hasHandler report whether the path is matched by some handlers (not prefix match)
+func (m *matcher) hasHandler() bool {
+	for _, v := range m.matches {
+		if !v.leaf.prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// casedPath return the path with the static segments in the casing of the first handler (not prefix match),
+// and report whether it is different from the path matched case-insensitively
+func (m *matcher) casedPath() (string, bool) {
+	for _, v := range m.matches {
+		if v.leaf.prefix {
+			continue
+		}
+
+		var b strings.Builder
+		last := 0
+		for i, s := range parsePattern(v.leaf.path) {
+			if i >= len(m.segs) || s.kind == catchAllSegment {
+				break
+			}
+			if s.kind == staticSegment && s.value != m.segs[i] {
+				b.WriteString(m.path[last:m.offsets[i]])
+				b.WriteString(s.value)
+				last = m.offsets[i] + len(m.segs[i])
+			}
+		}
+		if last == 0 {
+			return "", false
+		}
+		b.WriteString(m.path[last:])
+		return b.String(), true
+	}
+	return "", false
+}
+
+// methodNotAllowed report whether the path is matched by some handlers, but none of them accept the method
+func (m *matcher) methodNotAllowed() bool {
+	if len(m.allowed) == 0 {
+		return false
+	}
+	for _, v := range m.matches {
+		if !v.leaf.prefix {
+			return false
+		}
+	}
+	return true
+}
+
+// exitRouter return the index of the first match after i, which is not in the innermost Router of the i-th match
+func (m *matcher) exitRouter(i int) int {
+	mounts := m.matches[i].leaf.mounts
+	if len(mounts) == 0 {
+		return len(m.matches)
+	}
+
+	router := mounts[len(mounts)-1]
+	for j := i + 1; j < len(m.matches); j++ {
+		in := false
+		for _, v := range m.matches[j].leaf.mounts {
+			if v == router {
+				in = true
+				break
+			}
+		}
+		if !in {
+			return j
+		}
+	}
+	return len(m.matches)
+}
+
+// params return the params of the i-th match
+func (m *matcher) params(i int) map[string]string {
+	mt := m.matches[i]
+	params := make(map[string]string, len(mt.leaf.keys))
+	for j, key := range mt.leaf.keys {
+		params[key] = m.values[mt.start+j]
+	}
+	return params
+}
+
+// appendSegments append the non-empty segments of path to segs
+func appendSegments(segs []string, path string) []string {
+	for len(path) > 0 {
+		if path[0] == '/' {
+			path = path[1:]
+			continue
+		}
+		i := strings.IndexByte(path, '/')
+		if i < 0 {
+			return append(segs, path)
+		}
+		segs = append(segs, path[:i])
+		path = path[i:]
+	}
+	return segs
+}
+
+func splitPath(path string) []string {
+	return appendSegments(nil, path)
+}
+
+func joinRoutePath(prefix, path string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if path == "/" || path == "" {
+		if prefix == "" {
+			return "/"
+		}
+		return prefix
+	}
+	return prefix + path
+}
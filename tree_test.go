@@ -0,0 +1,200 @@
+package gor
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func lookupPaths(t *tree, method, requestPath string) (paths []string, params []map[string]string) {
+	m := acquireMatcher()
+	defer releaseMatcher(m)
+	t.lookup(m, method, requestPath)
+
+	for i, v := range m.matches {
+		paths = append(paths, v.leaf.path)
+		params = append(params, m.params(i))
+	}
+	return
+}
+
+func TestTree_lookup(t *testing.T) {
+	as := assert.New(t)
+	h := func(req *Req, res *Res) {}
+	hn := func(req *Req, res *Res, next Next) {}
+
+	app := NewRoute()
+	app.Use(hn)
+	app.Get("/a", h)
+	app.Get("/:name", h)
+	app.Use("/a", hn)
+	app.Post("/a", h)
+	app.Get("/a/:name/c", h)
+
+	paths, params := lookupPaths(app.tree, http.MethodGet, "/a")
+	as.Equal([]string{"/", "/a", "/:name", "/a"}, paths)
+	as.Equal([]map[string]string{{}, {}, {"name": "a"}, {}}, params)
+
+	paths, _ = lookupPaths(app.tree, http.MethodPost, "/a/")
+	as.Equal([]string{"/", "/a", "/a"}, paths)
+
+	paths, params = lookupPaths(app.tree, http.MethodGet, "/a/b/c")
+	as.Equal([]string{"/", "/a", "/a/:name/c"}, paths)
+	as.Equal(map[string]string{"name": "b"}, params[2])
+
+	paths, _ = lookupPaths(app.tree, http.MethodGet, "/b/c")
+	as.Equal([]string{"/"}, paths)
+}
+
+func TestTree_lookup_middleware_order(t *testing.T) {
+	as := assert.New(t)
+	h := func(req *Req, res *Res) {}
+	hn := func(req *Req, res *Res, next Next) {}
+
+	app := NewRoute()
+	app.Get("/:name", h)
+	app.Use("/admin", hn)
+	app.Get("/admin", h)
+	app.Get("/:id<int>", h)
+
+	// the handler is not moved ahead of the middleware registered before it
+	paths, _ := lookupPaths(app.tree, http.MethodGet, "/admin")
+	as.Equal([]string{"/:name", "/admin", "/admin"}, paths)
+
+	// the handlers without middleware between them are sorted by precedence
+	paths, _ = lookupPaths(app.tree, http.MethodGet, "/1")
+	as.Equal([]string{"/:id<int>", "/:name"}, paths)
+}
+
+func TestRoute_middleware_before_handler(t *testing.T) {
+	app, ts, e, _ := newTestServer(t)
+	defer ts.Close()
+
+	app.Get("/:name", func(req *Req, res *Res, next Next) { next() })
+	app.Use("/admin", func(req *Req, res *Res, next Next) {
+		if req.Query["token"] == nil {
+			res.SendStatus(http.StatusUnauthorized)
+			return
+		}
+		next()
+	})
+	app.Get("/admin", func(req *Req, res *Res) { res.Send("admin secret") })
+
+	e.GET("/admin").Expect().Status(http.StatusUnauthorized)
+	e.GET("/admin").WithQuery("token", "1").Expect().Status(http.StatusOK).Text().Equal("admin secret")
+}
+
+func TestTree_lookup_mount(t *testing.T) {
+	as := assert.New(t)
+	h := func(req *Req, res *Res) {}
+
+	sub := NewRouter()
+	sub.Get("/:id", h)
+	sub.Get("/", h)
+
+	router := NewRouter()
+	router.Get("/1", h)
+	router.Use("/sub/:version", sub)
+
+	app := NewRoute()
+	app.Use("/main/api", router)
+	app.Group("/group", func(group *Router) {
+		group.Get("/1", h)
+	})
+
+	paths, _ := lookupPaths(app.tree, http.MethodGet, "/main/api/1")
+	as.Equal([]string{"/main/api/1"}, paths)
+
+	paths, params := lookupPaths(app.tree, http.MethodGet, "/main/api/sub/v1/2")
+	as.Equal([]string{"/main/api/sub/:version/:id"}, paths)
+	as.Equal(map[string]string{"version": "v1", "id": "2"}, params[0])
+
+	paths, _ = lookupPaths(app.tree, http.MethodGet, "/main/api/sub/v1")
+	as.Equal([]string{"/main/api/sub/:version"}, paths)
+
+	paths, _ = lookupPaths(app.tree, http.MethodGet, "/group/1")
+	as.Equal([]string{"/group/1"}, paths)
+
+	paths, _ = lookupPaths(app.tree, http.MethodGet, "/main/1")
+	as.Len(paths, 0)
+}
+
+func TestTree_lookup_static_zero_alloc(t *testing.T) {
+	app := NewRoute()
+	for i := 0; i < 100; i++ {
+		app.Get(fmt.Sprintf("/static/%d/path", i), func(req *Req, res *Res) {})
+		app.Get(fmt.Sprintf("/param/%d/:name", i), func(req *Req, res *Res) {})
+	}
+	app.Use("/static", func(req *Req, res *Res, next Next) {})
+
+	m := acquireMatcher()
+	defer releaseMatcher(m)
+	app.tree.lookup(m, http.MethodGet, "/static/50/path")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		app.tree.lookup(m, http.MethodGet, "/static/50/path")
+	})
+	assert.Equal(t, float64(0), allocs)
+	assert.Len(t, m.matches, 2)
+}
+
+func BenchmarkTree_lookup(b *testing.B) {
+	app := NewRoute()
+	for i := 0; i < 300; i++ {
+		app.Get(fmt.Sprintf("/static/%d/path", i), func(req *Req, res *Res) {})
+		app.Get(fmt.Sprintf("/param/%d/:name", i), func(req *Req, res *Res) {})
+	}
+
+	m := acquireMatcher()
+	defer releaseMatcher(m)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		app.tree.lookup(m, http.MethodGet, "/static/299/path")
+	}
+}
+
+func TestTree_catchAll_optional(t *testing.T) {
+	as := assert.New(t)
+	h := func(req *Req, res *Res) {}
+
+	app := NewRoute()
+	app.Get("/files/*filepath", h)
+	app.Get("/files/:name", h)
+	app.Get("/files/readme", h)
+	app.Get("/posts/:id?", h)
+	app.Use("/opt/:a?", func(req *Req, res *Res, next Next) {})
+
+	paths, params := lookupPaths(app.tree, http.MethodGet, "/files/a/b/c/")
+	as.Equal([]string{"/files/*filepath"}, paths)
+	as.Equal(map[string]string{"filepath": "a/b/c"}, params[0])
+
+	paths, params = lookupPaths(app.tree, http.MethodGet, "/files/a")
+	as.Equal([]string{"/files/:name", "/files/*filepath"}, paths)
+	as.Equal([]map[string]string{{"name": "a"}, {"filepath": "a"}}, params)
+
+	paths, _ = lookupPaths(app.tree, http.MethodGet, "/files/readme")
+	as.Equal([]string{"/files/readme", "/files/:name", "/files/*filepath"}, paths)
+
+	paths, _ = lookupPaths(app.tree, http.MethodGet, "/files")
+	as.Len(paths, 0)
+
+	paths, params = lookupPaths(app.tree, http.MethodGet, "/posts")
+	as.Equal([]string{"/posts/:id?"}, paths)
+	as.Equal(map[string]string{}, params[0])
+
+	paths, params = lookupPaths(app.tree, http.MethodGet, "/posts/1")
+	as.Equal([]string{"/posts/:id?"}, paths)
+	as.Equal(map[string]string{"id": "1"}, params[0])
+
+	paths, params = lookupPaths(app.tree, http.MethodGet, "/opt/1/2")
+	as.Equal([]string{"/opt/:a?"}, paths)
+	as.Equal(map[string]string{"a": "1"}, params[0])
+
+	as.Panics(func() { app.Get("/files/*filepath/a", h) })
+	as.Panics(func() { app.Get("/posts/:id?/a", h) })
+	as.Panics(func() { app.Get("/posts/:", h) })
+}
@@ -0,0 +1,106 @@
+package gor
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+// URLFor build the url of the route registered with name, params fill the params of the pattern,
+// and query is encoded as query string
+//
+// the url of sub application is prefixed with MountPath
+func (g *Gor) URLFor(name string, params map[string]string, query map[string][]string) (string, error) {
+	l := g.tree.named(name)
+	if l == nil {
+		return "", fmt.Errorf("%w: %s", ErrRouteNotFound, name)
+	}
+	return buildURL(g.urlSegments(l), l.route.trailingSlash, params, query)
+}
+
+// urlSegments return the segments of the route, prefixed with the segments of MountPath
+func (g *Gor) urlSegments(l *leaf) []segment {
+	if mountPath := g.MountPath(); mountPath != "" {
+		return append(parsePattern(mountPath), l.segments...)
+	}
+	return l.segments
+}
+
+func buildURL(segments []segment, trailingSlash bool, params map[string]string, query map[string][]string) (string, error) {
+	var path []string
+	for _, seg := range segments {
+		if seg.kind == staticSegment {
+			path = append(path, url.PathEscape(seg.value))
+			continue
+		}
+
+		value, ok := params[seg.value]
+		if !ok || value == "" {
+			if seg.optional {
+				break
+			}
+			return "", fmt.Errorf("%w: %s", ErrURLParamMissing, seg.value)
+		}
+		if seg.matcher != nil {
+			if _, ok := seg.matcher(value); !ok {
+				return "", fmt.Errorf("%w: %s=%s", ErrURLParamInvalid, seg.value, value)
+			}
+		}
+
+		if seg.kind == catchAllSegment {
+			for _, v := range splitPath(value) {
+				path = append(path, url.PathEscape(v))
+			}
+		} else {
+			path = append(path, url.PathEscape(value))
+		}
+	}
+
+	u := "/" + strings.Join(path, "/")
+	if trailingSlash && len(path) > 0 {
+		u += "/"
+	}
+	if len(query) > 0 {
+		u += "?" + url.Values(query).Encode()
+	}
+	return u, nil
+}
+
+// templateFuncs return the funcs can be used in template rendered by Res.HTML
+//
+// urlFor: {{ urlFor "user.show" "id" 1 "tab" "info" }}, the pairs which are not params of the route are used as query
+func (g *Gor) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"urlFor": func(name string, pairs ...interface{}) (string, error) {
+			if len(pairs)%2 != 0 {
+				return "", fmt.Errorf("urlFor params must be key value pairs, but get %d", len(pairs))
+			}
+
+			l := g.tree.named(name)
+			if l == nil {
+				return "", fmt.Errorf("%w: %s", ErrRouteNotFound, name)
+			}
+
+			segments := g.urlSegments(l)
+			params := make(map[string]string)
+			query := make(map[string][]string)
+			for i := 0; i < len(pairs); i += 2 {
+				key, value := fmt.Sprint(pairs[i]), fmt.Sprint(pairs[i+1])
+				isParam := false
+				for _, seg := range segments {
+					if seg.kind != staticSegment && seg.value == key {
+						isParam = true
+						break
+					}
+				}
+				if isParam {
+					params[key] = value
+				} else {
+					query[key] = append(query[key], value)
+				}
+			}
+			return buildURL(segments, l.route.trailingSlash, params, query)
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package gor
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestURLFor(t *testing.T) {
+	app, ts, e, as := newTestServer(t)
+	defer ts.Close()
+
+	h := func(req *Req, res *Res) {}
+	app.Get("/", h).Name("index")
+	app.Get("/users/:id<int>", h).Name("user.show")
+	app.Get("/posts/:id?", h).Name("post")
+	app.Get("/files/*filepath", h).Name("file")
+	app.Group("/admin", func(group *Router) {
+		group.Get("/users/:name", h).Name("user")
+		group.Group("/sub", func(group *Router) {
+			group.Get("/", h).Name("index")
+		}).Name("sub.")
+	}).Name("admin.")
+	router := NewRouter()
+	router.Get("/a/:b", h).Name("router.a")
+	app.Use("/mount", router)
+
+	for _, v := range []struct {
+		name   string
+		params map[string]string
+		query  map[string][]string
+		url    string
+		err    error
+	}{
+		{"index", nil, nil, "/", nil},
+		{"index", nil, map[string][]string{"b": {"2"}, "a": {"1", "x y"}}, "/?a=1&a=x+y&b=2", nil},
+		{"user.show", map[string]string{"id": "1"}, nil, "/users/1", nil},
+		{"user.show", map[string]string{"id": "x"}, nil, "", ErrURLParamInvalid},
+		{"user.show", nil, nil, "", ErrURLParamMissing},
+		{"post", nil, nil, "/posts", nil},
+		{"post", map[string]string{"id": "a b"}, nil, "/posts/a%20b", nil},
+		{"file", map[string]string{"filepath": "a/b c.txt"}, nil, "/files/a/b%20c.txt", nil},
+		{"admin.user", map[string]string{"name": "chyroc"}, nil, "/admin/users/chyroc", nil},
+		{"admin.sub.index", nil, nil, "/admin/sub", nil},
+		{"router.a", map[string]string{"b": "1"}, nil, "/mount/a/1", nil},
+		{"not-exist", nil, nil, "", ErrRouteNotFound},
+	} {
+		u, err := app.URLFor(v.name, v.params, v.query)
+		as.Equal(v.url, u, v.name)
+		if v.err == nil {
+			as.Nil(err, v.name)
+		} else {
+			as.True(errors.Is(err, v.err), v.name)
+		}
+	}
+
+	app.SetRenderDir("testdata/url")
+	app.Get("/link/:id", func(req *Req, res *Res) { res.HTML("link", map[string]string{"ID": req.Params["id"]}) })
+	e.GET("/link/1").Expect().Status(http.StatusOK).Body().Equal(`<a href="/users/1?tab=info">1</a>`)
+	e.GET("/link/x").Expect().Status(http.StatusInternalServerError)
+}